@@ -0,0 +1,153 @@
+// Package analyze inspects handler source code for common concurrency mistakes.
+package analyze
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	"runtime"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// SafetyWarning describes a variable captured by a handler closure that may be shared between concurrent handler
+// invocations
+type SafetyWarning struct {
+	// Variable is the name of the captured variable
+	Variable string
+	// Kind is the kind of the captured variable, one of pointer, slice or map
+	Kind string
+	// Position is the location of the first use of the variable in the handler
+	Position token.Position
+}
+
+func (w SafetyWarning) String() string {
+	return fmt.Sprintf("%s: handler captures %s '%s' without holding a mutex", w.Position, w.Kind, w.Variable)
+}
+
+// HandlerSafetyCheck reports pointer, slice and map variables captured by the handler function literal fn that are
+// not protected by a mutex. A handler is considered protected if it calls Lock or RLock. Named functions and method
+// values do not capture variables so never produce warnings.
+//
+// The check loads and type checks the package the handler is declared in, so the source must be available at the path
+// recorded in the binary and the go command must be installed. It is intended to be run from tests.
+func HandlerSafetyCheck(fn interface{}) ([]SafetyWarning, error) {
+	if fn == nil || reflect.TypeOf(fn).Kind() != reflect.Func {
+		return nil, fmt.Errorf("'%v' is not a function", reflect.TypeOf(fn))
+	}
+	pc := reflect.ValueOf(fn).Pointer()
+	f := runtime.FuncForPC(pc)
+	if f == nil {
+		return nil, errors.New("unable to find handler function")
+	}
+	filename, line := f.FileLine(f.Entry())
+
+	pkg, file, err := loadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	lit := findFuncLit(pkg.Fset, file, line)
+	if lit == nil {
+		return nil, nil
+	}
+	if callsLock(lit) {
+		return nil, nil
+	}
+
+	var warnings []SafetyWarning
+	seen := make(map[*types.Var]bool)
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		v, ok := pkg.TypesInfo.Uses[ident].(*types.Var)
+		if !ok || v.IsField() || seen[v] || (v.Pos() >= lit.Pos() && v.Pos() < lit.End()) {
+			return true
+		}
+		seen[v] = true
+		if kind := typeKind(v.Type()); kind != "" {
+			warnings = append(warnings, SafetyWarning{
+				Variable: ident.Name,
+				Kind:     kind,
+				Position: pkg.Fset.Position(ident.Pos()),
+			})
+		}
+		return true
+	})
+	return warnings, nil
+}
+
+// loadFile loads and type checks the package containing the source file with the given name, including its test
+// files, and returns the package and the syntax tree of the file
+func loadFile(filename string) (*packages.Package, *ast.File, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes |
+			packages.NeedTypesInfo | packages.NeedImports,
+		Dir:   filepath.Dir(filename),
+		Tests: true,
+	}
+	pkgs, err := packages.Load(cfg, ".")
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load handler source: %w", err)
+	}
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) > 0 {
+			continue
+		}
+		for _, file := range pkg.Syntax {
+			if pkg.Fset.Position(file.Pos()).Filename == filename {
+				return pkg, file, nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("unable to load handler source '%s'", filename)
+}
+
+// findFuncLit returns the outermost function literal that starts on the given line
+func findFuncLit(fset *token.FileSet, file *ast.File, line int) *ast.FuncLit {
+	var found *ast.FuncLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		if lit, ok := n.(*ast.FuncLit); ok && fset.Position(lit.Pos()).Line == line {
+			found = lit
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// callsLock reports whether the function literal calls a Lock or RLock method
+func callsLock(lit *ast.FuncLit) bool {
+	var locks bool
+	ast.Inspect(lit.Body, func(n ast.Node) bool {
+		if call, ok := n.(*ast.CallExpr); ok {
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && (sel.Sel.Name == "Lock" || sel.Sel.Name == "RLock") {
+				locks = true
+			}
+		}
+		return !locks
+	})
+	return locks
+}
+
+// typeKind returns pointer, slice or map if t is one of those kinds, otherwise an empty string
+func typeKind(t types.Type) string {
+	switch t.Underlying().(type) {
+	case *types.Pointer:
+		return "pointer"
+	case *types.Slice:
+		return "slice"
+	case *types.Map:
+		return "map"
+	}
+	return ""
+}
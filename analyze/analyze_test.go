@@ -0,0 +1,118 @@
+package analyze_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/steinfletcher/bus/analyze"
+	"github.com/stretchr/testify/assert"
+)
+
+type GetUserQuery struct {
+	ID string
+}
+
+type cache struct {
+	users map[string]string
+}
+
+func namedHandler(ctx context.Context, query *GetUserQuery) error {
+	return nil
+}
+
+func TestHandlerSafetyCheck_UnsafeCaptures(t *testing.T) {
+	users := make(map[string]string)
+	var ids []string
+	c := &cache{}
+	count := 0
+
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		users[query.ID] = "Jan"
+		ids = append(ids, query.ID)
+		c.users = users
+		count++
+		return nil
+	}
+
+	warnings, err := analyze.HandlerSafetyCheck(handler)
+
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 3)
+	var captured []string
+	for _, warning := range warnings {
+		captured = append(captured, warning.Kind+" "+warning.Variable)
+		assert.Contains(t, warning.Position.Filename, "analyze_test.go")
+	}
+	assert.Equal(t, []string{"map users", "slice ids", "pointer c"}, captured)
+}
+
+var sharedUsers = map[string]string{}
+
+func newCache() *cache {
+	return &cache{users: make(map[string]string)}
+}
+
+func TestHandlerSafetyCheck_TypeChecked(t *testing.T) {
+	c := newCache()
+	ids := strings.Fields("1 2")
+
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		c.users[query.ID] = sharedUsers[query.ID]
+		ids[0] = query.ID
+		return nil
+	}
+
+	warnings, err := analyze.HandlerSafetyCheck(handler)
+
+	assert.NoError(t, err)
+	var captured []string
+	for _, warning := range warnings {
+		captured = append(captured, warning.Kind+" "+warning.Variable)
+	}
+	assert.Equal(t, []string{"pointer c", "map sharedUsers", "slice ids"}, captured)
+}
+
+func TestHandlerSafetyCheck_ProtectedByMutex(t *testing.T) {
+	users := make(map[string]string)
+	var mu sync.Mutex
+
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		mu.Lock()
+		defer mu.Unlock()
+		users[query.ID] = "Jan"
+		return nil
+	}
+
+	warnings, err := analyze.HandlerSafetyCheck(handler)
+
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestHandlerSafetyCheck_NoCaptures(t *testing.T) {
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		users := make(map[string]string)
+		users[query.ID] = "Jan"
+		return nil
+	}
+
+	warnings, err := analyze.HandlerSafetyCheck(handler)
+
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestHandlerSafetyCheck_NamedFunction(t *testing.T) {
+	warnings, err := analyze.HandlerSafetyCheck(namedHandler)
+
+	assert.NoError(t, err)
+	assert.Empty(t, warnings)
+}
+
+func TestHandlerSafetyCheck_NotAFunction(t *testing.T) {
+	_, err := analyze.HandlerSafetyCheck("not a func")
+
+	assert.EqualError(t, err, "'string' is not a function")
+}
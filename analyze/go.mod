@@ -0,0 +1,16 @@
+module github.com/steinfletcher/bus/analyze
+
+go 1.25.0
+
+require (
+	github.com/stretchr/testify v1.7.0
+	golang.org/x/tools v0.44.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.35.0 // indirect
+	golang.org/x/sync v0.20.0 // indirect
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
+)
@@ -0,0 +1,51 @@
+package bus
+
+import (
+	"context"
+	"reflect"
+)
+
+// WithArgProvider sets the function used to provide additional arguments to handlers. Handlers may declare
+// parameters after the message, which are set from the values returned by fn in order. For example
+//
+//	msgBus := bus.NewWithOptions(bus.WithArgProvider(func(ctx context.Context, msg bus.Message) []interface{} {
+//	   return []interface{}{logger}
+//	}))
+//
+//	msgBus.Subscribe(func(ctx context.Context, query *GetUserQuery, logger *slog.Logger) error {
+//	   return nil
+//	})
+//
+// Parameters without a corresponding value, or with a value that is not assignable to the parameter type, receive
+// the zero value. fn is called once per handler when the message is published.
+func WithArgProvider(fn func(ctx context.Context, msg Message) []interface{}) Option {
+	return func(e *eventBus) {
+		e.argProvider = fn
+	}
+}
+
+// handlerParams returns the params used to invoke the handler
+func (e *eventBus) handlerParams(handler handler, params []reflect.Value) []reflect.Value {
+	params = e.track(handler, params)
+	handlerType := handler.Handler.Type()
+	if handlerType.NumIn() <= len(params) {
+		return params
+	}
+
+	var args []interface{}
+	if e.argProvider != nil {
+		args = e.argProvider(params[0].Interface().(context.Context), params[1].Interface())
+	}
+	withArgs := make([]reflect.Value, handlerType.NumIn())
+	copy(withArgs, params)
+	for i := len(params); i < handlerType.NumIn(); i++ {
+		argType := handlerType.In(i)
+		withArgs[i] = reflect.Zero(argType)
+		if j := i - len(params); j < len(args) && args[j] != nil {
+			if arg := reflect.ValueOf(args[j]); arg.Type().AssignableTo(argType) {
+				withArgs[i] = arg
+			}
+		}
+	}
+	return withArgs
+}
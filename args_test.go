@@ -0,0 +1,52 @@
+package bus_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_WithArgProvider(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(buf, nil))
+	b := bus.NewWithOptions(bus.WithArgProvider(func(ctx context.Context, msg bus.Message) []interface{} {
+		return []interface{}{logger}
+	}))
+	var received *slog.Logger
+
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery, logger *slog.Logger) error {
+		received = logger
+		logger.Info("get user", "id", query.ID)
+		return nil
+	})
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Same(t, logger, received)
+	assert.Contains(t, buf.String(), "msg=\"get user\" id=1234")
+}
+
+func TestBus_WithArgProvider_MissingArgs(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithArgProvider(func(ctx context.Context, msg bus.Message) []interface{} {
+		return []interface{}{"not a logger"}
+	}))
+	var received *slog.Logger
+	var count int
+
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery, logger *slog.Logger, n int) error {
+		received = logger
+		count = n
+		return nil
+	})
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Nil(t, received)
+	assert.Equal(t, 0, count)
+}
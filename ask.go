@@ -0,0 +1,52 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrAskTimeout is returned by AskWithTimeout when no reply is published before the timeout
+var ErrAskTimeout = errors.New("timed out waiting for reply")
+
+// AskWithTimeout publishes request and waits for a reply of type *T to be published to b, for example by the handler
+// of request. The reply handler is subscribed before request is published and is unsubscribed when AskWithTimeout
+// returns. Replies are not correlated with requests, so the first *T published to b while waiting is returned. An
+// error wrapping ErrAskTimeout is returned if no reply is published within timeout, and ctx.Err() if ctx is done
+// first.
+func AskWithTimeout[T any](ctx context.Context, b Bus, request Message, timeout time.Duration) (*T, error) {
+	e, ok := b.(*eventBus)
+	if !ok {
+		return nil, errors.New("AskWithTimeout requires a bus created by New")
+	}
+	replies := make(chan *T, 1)
+	sub, err := e.subscribeHandle(func(ctx context.Context, reply *T) error {
+		select {
+		case replies <- reply:
+		default:
+		}
+		return nil
+	}, handler{ephemeral: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = sub.Unsubscribe()
+	}()
+
+	if err := b.Publish(ctx, request); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case reply := <-replies:
+		return reply, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-timer.C:
+		return nil, fmt.Errorf("%w: %T after %s", ErrAskTimeout, (*T)(nil), timeout)
+	}
+}
@@ -0,0 +1,61 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type GetUserReply struct {
+	ID   string
+	Name string
+}
+
+func TestAskWithTimeout(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return b.Publish(ctx, &GetUserReply{ID: query.ID, Name: "Jan"})
+	})
+
+	reply, err := bus.AskWithTimeout[GetUserReply](context.Background(), b, &GetUserQuery{ID: "1234"}, time.Second)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &GetUserReply{ID: "1234", Name: "Jan"}, reply)
+	assert.Len(t, b.Subscriptions(), 1)
+}
+
+func TestAskWithTimeout_AsyncReply(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		time.Sleep(10 * time.Millisecond)
+		_ = b.Publish(ctx, &GetUserReply{ID: query.ID})
+	})
+
+	reply, err := bus.AskWithTimeout[GetUserReply](context.Background(), b, &GetUserQuery{ID: "1234"}, time.Second)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &GetUserReply{ID: "1234"}, reply)
+}
+
+func TestAskWithTimeout_Timeout(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(getUserHandler)
+
+	_, err := bus.AskWithTimeout[GetUserReply](context.Background(), b, &GetUserQuery{ID: "1234"}, 10*time.Millisecond)
+
+	assert.True(t, errors.Is(err, bus.ErrAskTimeout))
+	assert.EqualError(t, err, "timed out waiting for reply: *bus_test.GetUserReply after 10ms")
+	assert.Len(t, b.Subscriptions(), 1)
+}
+
+func TestAskWithTimeout_PublishError(t *testing.T) {
+	b := bus.New()
+
+	_, err := bus.AskWithTimeout[GetUserReply](context.Background(), b, &GetUserQuery{ID: "1234"}, time.Second)
+
+	assert.Equal(t, bus.ErrHandlerNotFound, err)
+}
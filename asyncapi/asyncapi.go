@@ -0,0 +1,237 @@
+// Package asyncapi documents the subscriptions of a bus as an AsyncAPI 2.6 document. Each subscribed message type
+// becomes a channel that other services publish to, with a payload schema derived from the fields of the message
+// struct.
+package asyncapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the AsyncAPI specification version of the generated documents
+const Version = "2.6.0"
+
+// ServerInfo describes the service that owns the bus
+type ServerInfo struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []Server
+}
+
+// Server is a broker or endpoint that messages are published to, e.g. {Name: "production", URL:
+// "kafka.example.com:9092", Protocol: "kafka"}
+type Server struct {
+	Name        string
+	URL         string
+	Protocol    string
+	Description string
+}
+
+type document struct {
+	AsyncAPI   string             `yaml:"asyncapi"`
+	Info       info               `yaml:"info"`
+	Servers    map[string]server  `yaml:"servers,omitempty"`
+	Channels   map[string]channel `yaml:"channels"`
+	Components components         `yaml:"components"`
+}
+
+type info struct {
+	Title       string `yaml:"title"`
+	Version     string `yaml:"version"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type server struct {
+	URL         string `yaml:"url"`
+	Protocol    string `yaml:"protocol"`
+	Description string `yaml:"description,omitempty"`
+}
+
+type channel struct {
+	Description string            `yaml:"description,omitempty"`
+	Publish     operation         `yaml:"publish"`
+	Bindings    map[string]object `yaml:"bindings"`
+}
+
+type operation struct {
+	OperationID string            `yaml:"operationId"`
+	Message     ref               `yaml:"message"`
+	Bindings    map[string]object `yaml:"bindings"`
+}
+
+type ref struct {
+	Ref string `yaml:"$ref"`
+}
+
+type message struct {
+	Name        string `yaml:"name"`
+	Title       string `yaml:"title"`
+	ContentType string `yaml:"contentType"`
+	Payload     ref    `yaml:"payload"`
+}
+
+type components struct {
+	Messages map[string]message `yaml:"messages"`
+	Schemas  map[string]*schema `yaml:"schemas"`
+}
+
+type object map[string]interface{}
+
+type schema struct {
+	Type                 string             `yaml:"type,omitempty"`
+	Format               string             `yaml:"format,omitempty"`
+	Properties           map[string]*schema `yaml:"properties,omitempty"`
+	Items                *schema            `yaml:"items,omitempty"`
+	AdditionalProperties *schema            `yaml:"additionalProperties,omitempty"`
+}
+
+// bindingVersions are the versions of the AsyncAPI bindings generated for each transport
+var bindingVersions = map[string]string{
+	"http":  "0.3.0",
+	"kafka": "0.4.0",
+	"nats":  "0.1.0",
+}
+
+// GenerateYAML returns an AsyncAPI document in YAML describing the messages handled by b. There is a channel for each
+// message type named after the type, with an operation that documents the handlers subscribed to it. Channels and
+// operations include bindings for the HTTP, Kafka and NATS transports.
+func GenerateYAML(b bus.Inspector, serverInfo ServerInfo) ([]byte, error) {
+	doc := document{
+		AsyncAPI: Version,
+		Info: info{
+			Title:       serverInfo.Title,
+			Version:     serverInfo.Version,
+			Description: serverInfo.Description,
+		},
+		Channels: map[string]channel{},
+		Components: components{
+			Messages: map[string]message{},
+			Schemas:  map[string]*schema{},
+		},
+	}
+	for _, s := range serverInfo.Servers {
+		if doc.Servers == nil {
+			doc.Servers = map[string]server{}
+		}
+		doc.Servers[s.Name] = server{URL: s.URL, Protocol: s.Protocol, Description: s.Description}
+	}
+
+	handlers := map[string][]string{}
+	types := map[string]reflect.Type{}
+	for _, subscription := range b.Subscriptions() {
+		msgType := subscription.MessageType
+		for msgType.Kind() == reflect.Ptr {
+			msgType = msgType.Elem()
+		}
+		name := msgType.Name()
+		if name == "" {
+			name = msgType.String()
+		}
+		if existing, ok := types[name]; ok && existing != msgType {
+			return nil, fmt.Errorf("message types %s and %s have the same name", existing, msgType)
+		}
+		types[name] = msgType
+		handlers[name] = append(handlers[name], subscription.HandlerName)
+	}
+
+	for name, msgType := range types {
+		sort.Strings(handlers[name])
+		doc.Channels[name] = channel{
+			Description: "Handled by " + strings.Join(handlers[name], ", "),
+			Publish: operation{
+				OperationID: "publish" + name,
+				Message:     ref{Ref: "#/components/messages/" + name},
+				Bindings: map[string]object{
+					"http":  {"type": "request", "method": "POST", "bindingVersion": bindingVersions["http"]},
+					"kafka": {"bindingVersion": bindingVersions["kafka"]},
+					"nats":  {"bindingVersion": bindingVersions["nats"]},
+				},
+			},
+			Bindings: map[string]object{
+				"kafka": {"topic": name, "bindingVersion": bindingVersions["kafka"]},
+			},
+		}
+		doc.Components.Messages[name] = message{
+			Name:        name,
+			Title:       msgType.String(),
+			ContentType: "application/json",
+			Payload:     ref{Ref: "#/components/schemas/" + name},
+		}
+		doc.Components.Schemas[name] = schemaOf(msgType, map[reflect.Type]bool{})
+	}
+
+	return yaml.Marshal(doc)
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf returns the JSON schema of values of typ when encoded with encoding/json. seen holds the struct types
+// being described, recursive references to them are described as an object without properties
+func schemaOf(typ reflect.Type, seen map[reflect.Type]bool) *schema {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == timeType {
+		return &schema{Type: "string", Format: "date-time"}
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: schemaOf(typ.Elem(), seen)}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: schemaOf(typ.Elem(), seen)}
+	case reflect.Struct:
+		s := &schema{Type: "object"}
+		if seen[typ] {
+			return s
+		}
+		seen[typ] = true
+		defer delete(seen, typ)
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name, ok := jsonName(field)
+			if !ok {
+				continue
+			}
+			if s.Properties == nil {
+				s.Properties = map[string]*schema{}
+			}
+			s.Properties[name] = schemaOf(field.Type, seen)
+		}
+		return s
+	}
+	return &schema{}
+}
+
+// jsonName returns the name of the field when encoded with encoding/json, or false if the field is not encoded
+func jsonName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, true
+	}
+	return field.Name, true
+}
@@ -0,0 +1,100 @@
+package asyncapi_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/asyncapi"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type GetUserQuery struct {
+	ID     string
+	Result UserResult `json:"result"`
+}
+
+type UserResult struct {
+	Name      string    `json:"name"`
+	Email     string    `json:"email,omitempty"`
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"createdAt"`
+	password  string
+}
+
+type OrderPlaced struct {
+	ID     string
+	Amount float64
+	Items  map[string]int
+	Parent *OrderPlaced
+	Secret string `json:"-"`
+}
+
+func GetUser(ctx context.Context, query *GetUserQuery) error {
+	return nil
+}
+
+func SendReceipt(ctx context.Context, event OrderPlaced) {}
+
+func TestGenerateYAML(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(GetUser)
+	_ = b.SubscribeAsync(SendReceipt)
+
+	out, err := asyncapi.GenerateYAML(b, asyncapi.ServerInfo{
+		Title:   "Users",
+		Version: "1.0.0",
+		Servers: []asyncapi.Server{{Name: "production", URL: "kafka.example.com:9092", Protocol: "kafka"}},
+	})
+	assert.NoError(t, err)
+
+	var doc map[string]interface{}
+	assert.NoError(t, yaml.Unmarshal(out, &doc))
+	assert.Equal(t, "2.6.0", doc["asyncapi"])
+	assert.Equal(t, map[string]interface{}{"title": "Users", "version": "1.0.0"}, doc["info"])
+	assert.Equal(t, map[string]interface{}{
+		"production": map[string]interface{}{"url": "kafka.example.com:9092", "protocol": "kafka"},
+	}, doc["servers"])
+
+	channels := doc["channels"].(map[string]interface{})
+	assert.Len(t, channels, 2)
+	getUser := channels["GetUserQuery"].(map[string]interface{})
+	assert.Equal(t, "Handled by github.com/steinfletcher/bus/asyncapi_test.GetUser", getUser["description"])
+	publish := getUser["publish"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"$ref": "#/components/messages/GetUserQuery"}, publish["message"])
+	assert.Contains(t, publish["bindings"], "http")
+	assert.Contains(t, publish["bindings"], "kafka")
+	assert.Contains(t, publish["bindings"], "nats")
+
+	components := doc["components"].(map[string]interface{})
+	messages := components["messages"].(map[string]interface{})
+	assert.Contains(t, messages, "GetUserQuery")
+	assert.Contains(t, messages, "OrderPlaced")
+	schemas := components["schemas"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"ID": map[string]interface{}{"type": "string"},
+			"result": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":      map[string]interface{}{"type": "string"},
+					"email":     map[string]interface{}{"type": "string"},
+					"roles":     map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+					"createdAt": map[string]interface{}{"type": "string", "format": "date-time"},
+				},
+			},
+		},
+	}, schemas["GetUserQuery"])
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"ID":     map[string]interface{}{"type": "string"},
+			"Amount": map[string]interface{}{"type": "number"},
+			"Items":  map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"type": "integer"}},
+			"Parent": map[string]interface{}{"type": "object"},
+		},
+	}, schemas["OrderPlaced"])
+}
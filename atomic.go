@@ -0,0 +1,49 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// AtomicPublish publishes each message to its bus, or to none of them. pairs are [Bus, Message] pairs. In the prepare
+// phase each bus is checked for a handler of its message, if any bus has no handler an error wrapping
+// ErrHandlerNotFound is returned and no messages are published. In the commit phase every message is published, even
+// if publishing an earlier message fails, and the errors returned by the buses are joined.
+//
+// Only handler lookup is atomic. Handlers that fail during the commit phase are not compensated, and buses that are
+// not created by New are assumed to be prepared.
+func AtomicPublish(ctx context.Context, pairs ...[2]interface{}) error {
+	buses := make([]Bus, len(pairs))
+	for i, pair := range pairs {
+		b, ok := pair[0].(Bus)
+		if !ok {
+			return fmt.Errorf("pair %d: '%T' is not a Bus", i, pair[0])
+		}
+		if pair[1] == nil {
+			return fmt.Errorf("pair %d: message is nil", i)
+		}
+		buses[i] = b
+	}
+
+	for i, b := range buses {
+		if e, ok := b.(*eventBus); ok && !e.hasHandlers(ctx, pairs[i][1]) {
+			return fmt.Errorf("prepare pair %d: %s: %w", i, reflect.TypeOf(pairs[i][1]), ErrHandlerNotFound)
+		}
+	}
+
+	var errs []error
+	for i, b := range buses {
+		if err := b.Publish(ctx, pairs[i][1]); err != nil {
+			errs = append(errs, fmt.Errorf("commit pair %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hasHandlers reports whether publishing msg with ctx would dispatch it to at least one handler
+func (e *eventBus) hasHandlers(ctx context.Context, msg Message) bool {
+	_, _, ok := e.lookupHandlers(ctx, reflect.TypeOf(msg).String(), msg)
+	return ok
+}
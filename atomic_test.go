@@ -0,0 +1,96 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicPublish(t *testing.T) {
+	orders, users := bus.New(), bus.New()
+	var dispatched []string
+	_ = orders.Subscribe(func(ctx context.Context, cmd *SomeCommand) error {
+		dispatched = append(dispatched, "orders")
+		return nil
+	})
+	_ = users.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		dispatched = append(dispatched, "users")
+		return nil
+	})
+
+	err := bus.AtomicPublish(context.Background(),
+		[2]interface{}{orders, &SomeCommand{}},
+		[2]interface{}{users, &GetUserQuery{ID: "1234"}},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orders", "users"}, dispatched)
+}
+
+func TestAtomicPublish_InterfaceHandlers(t *testing.T) {
+	orders, audit := bus.New(), bus.New()
+	var dispatched []string
+	_ = orders.Subscribe(func(ctx context.Context, event DomainEvent) error {
+		dispatched = append(dispatched, "orders")
+		return nil
+	})
+	_ = audit.SubscribeAll(func(ctx context.Context, msg bus.Message) error {
+		dispatched = append(dispatched, "audit")
+		return nil
+	})
+
+	err := bus.AtomicPublish(context.Background(),
+		[2]interface{}{orders, &OrderCreated{ID: "1"}},
+		[2]interface{}{audit, &GetUserQuery{ID: "1234"}},
+	)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"orders", "audit"}, dispatched)
+}
+
+func TestAtomicPublish_PrepareFails(t *testing.T) {
+	orders, users := bus.New(), bus.New()
+	var dispatched bool
+	_ = orders.Subscribe(func(ctx context.Context, cmd *SomeCommand) error {
+		dispatched = true
+		return nil
+	})
+
+	err := bus.AtomicPublish(context.Background(),
+		[2]interface{}{orders, &SomeCommand{}},
+		[2]interface{}{users, &GetUserQuery{ID: "1234"}},
+	)
+
+	assert.True(t, errors.Is(err, bus.ErrHandlerNotFound))
+	assert.EqualError(t, err, "prepare pair 1: *bus_test.GetUserQuery: handler not found")
+	assert.False(t, dispatched)
+}
+
+func TestAtomicPublish_CommitErrors(t *testing.T) {
+	orders, users := bus.New(), bus.New()
+	var dispatched bool
+	_ = orders.Subscribe(func(ctx context.Context, cmd *SomeCommand) error {
+		return errFatal
+	})
+	_ = users.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		dispatched = true
+		return nil
+	})
+
+	err := bus.AtomicPublish(context.Background(),
+		[2]interface{}{orders, &SomeCommand{}},
+		[2]interface{}{users, &GetUserQuery{ID: "1234"}},
+	)
+
+	assert.True(t, errors.Is(err, errFatal))
+	assert.True(t, dispatched)
+}
+
+func TestAtomicPublish_InvalidPair(t *testing.T) {
+	err := bus.AtomicPublish(context.Background(), [2]interface{}{"bus", &GetUserQuery{}})
+
+	assert.EqualError(t, err, "pair 0: 'string' is not a Bus")
+}
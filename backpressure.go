@@ -0,0 +1,39 @@
+package bus
+
+import "errors"
+
+// ErrQueueFull is returned by Publish when the queue of an async handler using BackpressureError is full
+var ErrQueueFull = errors.New("async handler queue is full")
+
+// errMessageDropped is returned by asyncQueue.Push when a message is discarded to make room in the queue
+var errMessageDropped = errors.New("message dropped")
+
+// BackpressurePolicy describes how a message is queued for an async handler whose queue is full
+type BackpressurePolicy int
+
+const (
+	// BackpressureBlock blocks the publisher until there is room in the queue
+	BackpressureBlock BackpressurePolicy = iota
+	// BackpressureDropNewest discards the published message
+	BackpressureDropNewest
+	// BackpressureDropOldest discards the oldest message in the queue to make room for the published message
+	BackpressureDropOldest
+	// BackpressureError returns an error wrapping ErrQueueFull to the publisher. Handlers that follow the handler with the
+	// full queue are not dispatched
+	BackpressureError
+)
+
+// WithDefaultBackpressure sets the policy used when the queue of an async handler is full, for handlers subscribed
+// without WithBackpressure. The default is BackpressureBlock. Dropped messages are logged as warnings
+func WithDefaultBackpressure(policy BackpressurePolicy) Option {
+	return func(e *eventBus) {
+		e.backpressure = policy
+	}
+}
+
+// WithBackpressure sets the policy used when the queue of the subscribed async handler is full
+func WithBackpressure(policy BackpressurePolicy) SubscribeOption {
+	return func(h *handler) {
+		h.backpressure = &policy
+	}
+}
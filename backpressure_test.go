@@ -0,0 +1,89 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fillQueue subscribes a handler with a queue of size 2 and publishes four messages while the handler is blocked on
+// the first, so that the fourth message overflows the queue. It returns the IDs handled once the handler is released
+func fillQueue(t *testing.T, b bus.Bus, opts ...bus.SubscribeOption) ([]string, error) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	handled := make(chan string, 4)
+	sub, err := b.SubscribeAsyncHandle(func(ctx context.Context, query *GetUserQuery) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		handled <- query.ID
+	}, opts...)
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1"}))
+	<-started
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "2"}))
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "3"}))
+	assert.Equal(t, 2, sub.QueueDepth())
+	publishErr := b.Publish(context.Background(), &GetUserQuery{ID: "4"})
+	assert.Equal(t, 2, sub.QueueDepth())
+
+	close(release)
+	assert.NoError(t, b.Close(context.Background()))
+	close(handled)
+	var ids []string
+	for id := range handled {
+		ids = append(ids, id)
+	}
+	return ids, publishErr
+}
+
+func TestBus_WithBackpressure_DropNewest(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDefaultQueueSize(2))
+
+	ids, err := fillQueue(t, b, bus.WithBackpressure(bus.BackpressureDropNewest))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+}
+
+func TestBus_WithBackpressure_DropOldest(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDefaultQueueSize(2))
+
+	ids, err := fillQueue(t, b, bus.WithBackpressure(bus.BackpressureDropOldest))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "3", "4"}, ids)
+}
+
+func TestBus_WithBackpressure_Error(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDefaultQueueSize(2))
+
+	ids, err := fillQueue(t, b, bus.WithBackpressure(bus.BackpressureError))
+
+	assert.ErrorIs(t, err, bus.ErrQueueFull)
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+}
+
+func TestBus_WithDefaultBackpressure(t *testing.T) {
+	logger := &testLogger{}
+	b := bus.NewWithOptions(bus.WithDefaultQueueSize(2), bus.WithDefaultBackpressure(bus.BackpressureDropNewest), bus.WithLogger(logger))
+
+	ids, err := fillQueue(t, b)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+	assert.Contains(t, logger.Messages()[0], "WARN queue of async handler")
+}
+
+func TestBus_WithBackpressure_OverridesDefault(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDefaultQueueSize(2), bus.WithDefaultBackpressure(bus.BackpressureDropNewest))
+
+	_, err := fillQueue(t, b, bus.WithBackpressure(bus.BackpressureError))
+
+	assert.ErrorIs(t, err, bus.ErrQueueFull)
+}
@@ -0,0 +1,69 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExceeded is returned when publishing a message whose handlers have used their execution budget for the
+// current period
+var ErrBudgetExceeded = errors.New("handler execution budget exceeded")
+
+// WithCumulativeBudget limits the total time handlers of msgType may run for within each reset period. Once the
+// budget is used, publishing msgType returns ErrBudgetExceeded until the period ends. msgType is given as a sample
+// value, e.g. &GetUserQuery{}. Handlers that are already running are not interrupted. It panics if budget or reset is
+// not positive.
+func WithCumulativeBudget(msgType interface{}, budget time.Duration, reset time.Duration) Option {
+	switch {
+	case budget <= 0:
+		panic(fmt.Sprintf("execution budget must be positive, got %s", budget))
+	case reset <= 0:
+		panic(fmt.Sprintf("execution budget reset period must be positive, got %s", reset))
+	}
+	return func(e *eventBus) {
+		if e.budgets == nil {
+			e.budgets = make(map[string]*executionBudget)
+		}
+		e.budgets[reflect.TypeOf(msgType).String()] = &executionBudget{
+			budget: budget,
+			reset:  reset,
+			start:  time.Now(),
+		}
+	}
+}
+
+// executionBudget tracks the cumulative handler execution time in the current period
+type executionBudget struct {
+	sync.Mutex
+	budget time.Duration
+	reset  time.Duration
+	start  time.Time
+	used   time.Duration
+}
+
+// Record adds the handler execution time to the current period
+func (b *executionBudget) Record(d time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+	b.rollover()
+	b.used += d
+}
+
+// Exceeded reports whether the budget for the current period has been used
+func (b *executionBudget) Exceeded() bool {
+	b.Lock()
+	defer b.Unlock()
+	b.rollover()
+	return b.used >= b.budget
+}
+
+// rollover starts a new period if the current period has ended
+func (b *executionBudget) rollover() {
+	if elapsed := time.Since(b.start); elapsed >= b.reset {
+		b.start = b.start.Add(elapsed - elapsed%b.reset)
+		b.used = 0
+	}
+}
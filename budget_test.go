@@ -0,0 +1,39 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_WithCumulativeBudget(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithCumulativeBudget(&GetUserQuery{}, 100*time.Millisecond, 500*time.Millisecond))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		time.Sleep(60 * time.Millisecond)
+		return nil
+	})
+	_ = b.Subscribe(func(ctx context.Context, command SomeCommand) error {
+		return nil
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+	assert.Equal(t, bus.ErrBudgetExceeded, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+	assert.NoError(t, b.Publish(context.Background(), SomeCommand{ID: "1234"}))
+
+	time.Sleep(500 * time.Millisecond)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+}
+
+func TestWithCumulativeBudget_InvalidArguments(t *testing.T) {
+	assert.PanicsWithValue(t, "execution budget must be positive, got 0s", func() {
+		bus.WithCumulativeBudget(&GetUserQuery{}, 0, time.Second)
+	})
+	assert.PanicsWithValue(t, "execution budget reset period must be positive, got 0s", func() {
+		bus.WithCumulativeBudget(&GetUserQuery{}, time.Second, 0)
+	})
+}
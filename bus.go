@@ -4,8 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"reflect"
+	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
 )
 
 // Bus exposes the Subscriber and Publisher and is the main interface used to interact with the message bus.
@@ -27,6 +35,92 @@ import (
 type Bus interface {
 	Subscriber
 	Publisher
+	Inspector
+
+	// Close stops the bus accepting publishes and subscriptions, which return ErrBusClosed, and waits for publishes in
+	// progress and the messages queued for async handlers to be handled. If ctx is done first ctx.Err() is returned and
+	// the queued messages that have not been handled are discarded. Handlers that are running are not interrupted
+	Close(ctx context.Context) error
+
+	// Use adds mw to the middleware that wraps every handler invocation, including handlers that are already
+	// subscribed. Middleware run in the order they were added, each calling next to invoke the following middleware
+	// and finally the handler. An error returned by the middleware chain is handled as an error returned by the handler
+	Use(mw Middleware)
+
+	// AddFilter appends f to the filters run, in the order they were added, before the handlers of a published message
+	// are looked up. See MessageFilter
+	AddFilter(f MessageFilter)
+}
+
+// Inspector exposes the handlers subscribed to the bus. It is useful for tooling such as documentation generators and
+// schema checks that need to know which messages the bus handles.
+type Inspector interface {
+	// Subscriptions returns a description of each subscribed handler ordered by message type name and then by the
+	// order the handlers are called, see WithPriority
+	Subscriptions() []SubscriptionInfo
+}
+
+// SubscriptionInfo describes a handler subscribed to the bus
+type SubscriptionInfo struct {
+	// MessageType is the type of message handled by the handler
+	MessageType reflect.Type
+	// HandlerName is the fully qualified name of the handler function
+	HandlerName string
+	// Async is true if the handler was subscribed asynchronously
+	Async bool
+	// Tenant is the tenant the handler was subscribed for, empty for handlers subscribed for all tenants
+	Tenant string
+	// Kind is the way the handler was subscribed, which decides the publishes it receives
+	Kind SubscriptionKind
+	// Topic is the topic pattern of KindTopic handlers, see SubscribeTopic
+	Topic string
+	// RawMessageType is the message type name of KindRaw handlers, see SubscribeRaw
+	RawMessageType string
+	// Codec decodes the messages of KindRaw handlers
+	Codec Codec
+}
+
+// SubscriptionKind is the way a handler was subscribed, which decides the publishes it receives
+type SubscriptionKind int
+
+const (
+	// KindType handlers are subscribed to a message type, e.g. with Subscribe, and receive the messages published by
+	// type, e.g. with Publish
+	KindType SubscriptionKind = iota
+	// KindTopic handlers are subscribed with SubscribeTopic and receive the messages published with PublishTopic
+	KindTopic
+	// KindRaw handlers are subscribed with SubscribeRaw and receive the data published with PublishRaw
+	KindRaw
+	// KindRequest handlers are subscribed with SubscribeRequest and receive the queries published with Request
+	KindRequest
+)
+
+var subscriptionKindNames = [...]string{"type", "topic", "raw", "request"}
+
+func (k SubscriptionKind) String() string {
+	if k < 0 || int(k) >= len(subscriptionKindNames) {
+		return fmt.Sprintf("SubscriptionKind(%d)", int(k))
+	}
+	return subscriptionKindNames[k]
+}
+
+// MarshalText encodes the kind as its name, e.g. topic
+func (k SubscriptionKind) MarshalText() ([]byte, error) {
+	if k < 0 || int(k) >= len(subscriptionKindNames) {
+		return nil, fmt.Errorf("invalid subscription kind %d", int(k))
+	}
+	return []byte(subscriptionKindNames[k]), nil
+}
+
+// UnmarshalText decodes a kind encoded by MarshalText
+func (k *SubscriptionKind) UnmarshalText(text []byte) error {
+	for i, name := range subscriptionKindNames {
+		if name == string(text) {
+			*k = SubscriptionKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid subscription kind '%s'", text)
 }
 
 // Subscriber listens to events published to the bus. Use Subscribe to listen to events synchronously and
@@ -36,9 +130,16 @@ type Bus interface {
 // The Message type must match the handler subscriber type. Pointer and
 // non-pointer messages are considered as separate types - internally subscribers are keyed using the message type which
 // includes a pointer symbol in the lookup key.
+// A handler whose message type is an interface, e.g. func(ctx context.Context, event DomainEvent) error, receives every
+// published message that implements the interface, after the handlers of the message type unless ordered otherwise by
+// WithPriority. Interface handlers subscribed for a tenant are not called.
 type Subscriber interface {
-	// Subscribe is used to listen to events synchronously
-	Subscribe(fn interface{}) error
+	// Subscribe is used to listen to events synchronously. opts configure the subscription, e.g. WithMiddleware
+	Subscribe(fn interface{}, opts ...SubscribeOption) error
+
+	// SubscribeCtx is used to listen to events synchronously. It returns ctx.Err() if ctx is done while waiting for
+	// the subscription rate limit, see WithSubscriptionRateLimit
+	SubscribeCtx(ctx context.Context, fn interface{}) error
 
 	// MustSubscribe is used to listen to events synchronously. This method simplifies subscription but panics internally
 	// if there are no subscribers. It is recommended to only use this for defining static relationships rather than
@@ -46,97 +147,870 @@ type Subscriber interface {
 	MustSubscribe(fn interface{})
 
 	// SubscribeAsync is used to listen to events asynchronously. Subscribers are run in a separate go routine and data
-	// is passed into the subscriber via a channel
-	SubscribeAsync(fn interface{}) error
+	// is passed into the subscriber via a channel. opts configure the subscription, e.g. WithMiddleware
+	SubscribeAsync(fn interface{}, opts ...SubscribeOption) error
 
 	// MustSubscribeAsync is used to listen to events asynchronously. This method simplifies subscription but panics internally
 	// if there are no subscribers. It is recommended to only use this for defining static relationships rather than
 	// dynamic relationships defined at runtime
 	MustSubscribeAsync(fn interface{})
+
+	// SubscribeTenant is used to listen to events synchronously for a single tenant. The tenant is extracted from the
+	// publish context using the extractor configured with WithTenantExtractor. If no handler is registered for the
+	// tenant the message is dispatched to the handlers registered with Subscribe
+	SubscribeTenant(tenantID string, fn interface{}) error
+
+	// SubscribeAtMostOnce is used to listen to events synchronously where the handler is invoked at most once for each
+	// key returned by keyFn within the given window. Messages with a key that has already been processed are skipped
+	SubscribeAtMostOnce(fn interface{}, keyFn func(Message) string, window time.Duration) error
+
+	// SubscribeWithAdvisoryTimeout is used to listen to events synchronously where warn is called if the handler runs
+	// for longer than d. The handler is not interrupted and its result is still returned to the publisher
+	SubscribeWithAdvisoryTimeout(fn interface{}, d time.Duration, warn func(handlerName string, elapsed time.Duration)) error
+
+	// ReplaceAllHandlers atomically replaces the handlers for msgType with fns, which are subscribed synchronously.
+	// msgType is given as a sample value, e.g. &GetUserQuery{}, or for an interface type as a nil pointer to the
+	// interface, e.g. (*DomainEvent)(nil). Each publish is dispatched either to the replaced handlers or to fns, never
+	// to a mix of both. fns are subscribed like Subscribe, subject to the subscription rate limit and the duplicate
+	// subscription check, see WithSubscriptionRateLimit and WithDuplicateSubscriptionCheck. Messages queued for
+	// replaced async handlers are drained before the method returns, an error is returned if draining takes longer
+	// than 5 seconds
+	ReplaceAllHandlers(msgType interface{}, fns ...interface{}) error
+
+	// SubscribeCoalesced is used to listen to events asynchronously where messages that arrive in quick succession are
+	// merged. The handler is called with the last message once no messages have been published for the debounce
+	// duration. Handler errors are not returned to the publisher, they are handled as the errors of async handlers.
+	// Close calls the handler with a pending message without waiting for the debounce duration
+	SubscribeCoalesced(fn interface{}, debounce time.Duration) error
+
+	// SubscribeScheduled is used to listen to events synchronously where the handler is only active during the given
+	// time windows. Messages published outside the windows are not dispatched to the handler
+	SubscribeScheduled(fn interface{}, windows []TimeWindow) (Subscription, error)
+
+	// SubscribeJSONPath is used to listen to events synchronously where the handler is only called if evaluating the
+	// JSONPath expression against the JSON encoded message selects at least one value, e.g.
+	// $[?(@.ID == "1234")]. An error is returned if the expression is invalid
+	SubscribeJSONPath(jsonPathExpr string, fn interface{}) error
+
+	// SubscribeRaw is used to listen synchronously to messages published with PublishRaw for msgTypeName. The data of
+	// each message is decoded by codec into a new value of the handler message type before the handler is called.
+	// fn must return an error, decoding errors are returned to the publisher. opts configure the subscription, e.g.
+	// WithSingleHandler
+	SubscribeRaw(msgTypeName string, codec Codec, fn interface{}, opts ...SubscribeOption) error
+
+	// SubscribeWithJitter is used to listen to events asynchronously where each handler invocation is delayed by a
+	// random duration in [0, maxJitter). This spreads the load when many handlers receive the same message at once
+	SubscribeWithJitter(fn interface{}, maxJitter time.Duration) error
+
+	// SubscribeDeduped is used to listen to events synchronously where a message is skipped if eq reports that it is
+	// equal to the previous message dispatched to the handler. Only consecutive duplicates are skipped
+	SubscribeDeduped(fn interface{}, eq func(a, b Message) bool) error
+
+	// SubscribeHandle is used to listen to events synchronously like Subscribe and returns a handle that removes the
+	// handler when unsubscribed
+	SubscribeHandle(fn interface{}, opts ...SubscribeOption) (Subscription, error)
+
+	// SubscribeAsyncHandle is used to listen to events asynchronously like SubscribeAsync and returns a handle that
+	// removes the handler when unsubscribed
+	SubscribeAsyncHandle(fn interface{}, opts ...SubscribeOption) (Subscription, error)
+
+	// SubscribeDeadLetter calls fn with each message that could not be handled, under the same conditions as
+	// WithDeadLetterBus. fn is called in the go routine that dead lettered the message
+	SubscribeDeadLetter(fn func(ctx context.Context, envelope DeadLetterEnvelope)) error
+
+	// SubscribeTopic is used to listen synchronously to messages published with PublishTopic to topics matching
+	// pattern. Topics are dot separated names such as "user.created" and a "*" segment of the pattern matches any
+	// single segment, e.g. "user.*". Only messages of the handler message type are dispatched to the handler, so a
+	// pattern can match topics carrying different message types. Handlers are called in the order of their patterns
+	SubscribeTopic(pattern string, fn interface{}) error
+
+	// SubscribeRequest is used to answer requests sent with Request. fn must have the signature
+	// func(ctx context.Context, query *Query) (Result, error) and is called synchronously. A message type has at most
+	// one request handler, an error wrapping ErrMultipleHandlers is returned if one is already subscribed. Request
+	// handlers are kept separately from the handlers subscribed to the message type with the other Subscribe methods.
+	// opts configure the subscription, e.g. WithTimeout
+	SubscribeRequest(fn interface{}, opts ...SubscribeOption) error
+
+	// SubscribeAll is used to listen synchronously to every message published to the bus, whatever its type. It is
+	// equivalent to subscribing fn to the Message interface, so fn is called after the handlers of the message type
+	// unless ordered otherwise by WithPriority. Messages published with PublishTopic, PublishRaw and Request are not
+	// passed to fn
+	SubscribeAll(fn func(ctx context.Context, msg Message) error, opts ...SubscribeOption) error
+
+	// Unsubscribe removes every handler subscribed with fn, including handlers subscribed for tenants. Functions are
+	// compared by pointer, so closures created by the same function literal are all removed. Messages queued for
+	// async handlers that have not been handled are discarded. ErrSubscriptionNotFound is returned if fn is not
+	// subscribed. Method values created with reflect and functions created with reflect.MakeFunc cannot be told
+	// apart by pointer, ErrUnidentifiableHandler is returned for them and they are unsubscribed with the Subscription
+	// returned by SubscribeHandle instead
+	Unsubscribe(fn interface{}) error
 }
 
 // Publisher publishes an event to the bus. The Message type must match the handler subscriber type. Pointer and
 //// non-pointer messages are considered as separate types - internally subscribers are keyed using the message type which
 //// includes a pointer symbol in the lookup key.
 type Publisher interface {
+	// Publish dispatches msg to its handlers. If ctx is done no further handlers are called or queued and ctx.Err() is
+	// returned, unless the bus is created with WithIsolatedContext
 	Publish(ctx context.Context, msg Message) error
+
+	// PublishOptional publishes a message like Publish but returns nil rather than ErrHandlerNotFound if the message
+	// has no handlers, and does not dead letter it. It is intended for events that nothing may be subscribed to yet
+	PublishOptional(ctx context.Context, msg Message) error
+
+	// PublishAsync publishes a message like Publish in a new go routine and returns immediately. The error returned by
+	// the publish, nil if it succeeds, is sent on the returned channel, which is then closed. The channel is buffered
+	// so it need not be read. The publish is cancelled if ctx is done, use context.WithoutCancel to publish beyond
+	// the lifetime of a request. Close waits for the publish to complete
+	PublishAsync(ctx context.Context, msg Message) <-chan error
+
+	// PublishBatch publishes each of msgs like Publish, in order. Messages may be of different types. Every message is
+	// published even if publishing an earlier message fails, and the errors are returned joined, see errors.Join, each
+	// wrapped with the index and type of its message. If ctx is done the remaining messages are not published and
+	// ctx.Err() is returned joined with the errors so far. A batch that has started is completed even if the bus is
+	// closed. The batch is not published under a single lock acquisition, so subscriptions and publishes on other
+	// goroutines may interleave with it and each message is dispatched to the handlers subscribed when it is published
+	PublishBatch(ctx context.Context, msgs ...Message) error
+
+	// PublishWithClassifier publishes a message and uses classify to decide how to handle each error returned by a
+	// sync handler. Publish behaves as if every error is classified as ActionStop
+	PublishWithClassifier(ctx context.Context, msg Message, classify func(err error) ErrorAction) error
+
+	// PublishRaw publishes encoded message data to the handlers subscribed with SubscribeRaw for msgTypeName. Handlers
+	// subscribed to the message type with the other Subscribe methods are not called
+	PublishRaw(ctx context.Context, msgTypeName string, data []byte) error
+
+	// PublishSync publishes a message and calls every handler, including handlers subscribed asynchronously, in the
+	// publishing go routine. It returns once all handlers have completed, so the effects of async handlers are visible
+	// to the caller. Errors returned by async handlers are returned to the publisher rather than dead lettered
+	PublishSync(ctx context.Context, msg Message) error
+
+	// PublishTopic publishes a message to the handlers subscribed with SubscribeTopic to a pattern matching topic.
+	// ErrHandlerNotFound is returned if no pattern matches topic
+	PublishTopic(ctx context.Context, topic string, msg Message) error
+
+	// PublishWith publishes a message through the given middleware, which apply to this call only. The first
+	// middleware runs first and the message is published when the last middleware calls next, so they run before the
+	// middleware added with Use
+	PublishWith(ctx context.Context, msg Message, mws ...Middleware) error
+
+	// Request sends query to the handler subscribed with SubscribeRequest and returns the result and error of the
+	// handler. ErrHandlerNotFound is returned if no request handler is subscribed for the message type
+	Request(ctx context.Context, query Message) (interface{}, error)
 }
 
+// ErrorAction describes how the bus handles an error returned by a handler
+type ErrorAction int
+
+const (
+	// ActionStop ends the handler chain and returns the error to the publisher
+	ActionStop ErrorAction = iota
+	// ActionContinue ignores the error and invokes the next handler
+	ActionContinue
+	// ActionRetry invokes the handler again after the Backoff of the retry policy. The handler is invoked at most
+	// MaxAttempts times, or defaultClassifierAttempts times if the policy does not retry, after which the error ends
+	// the handler chain. The classifier is consulted for each failed attempt
+	ActionRetry
+	// ActionDeadLetter dead letters the message with the error and invokes the next handler, see WithDeadLetterBus
+	// and SubscribeDeadLetter
+	ActionDeadLetter
+)
+
+// defaultClassifierAttempts is the number of times a handler is invoked while its errors are classified as ActionRetry
+// when the retry policy does not retry
+const defaultClassifierAttempts = 3
+
 // ErrHandlerNotFound is returned when publishing an event that does not have any subscribers
 var ErrHandlerNotFound = errors.New("handler not found")
 
+// ErrNonStructMessage is returned when subscribing a handler whose message argument is not a struct, a pointer to a
+// struct or an interface. Primitive types such as string do not describe what the message means so cannot be used as
+// messages
+var ErrNonStructMessage = errors.New("message must be a struct")
+
 // Message the data that is published. The implementing type is used as the handler key
 type Message interface{}
 
-// New create a new message bus.
+// Option configures the message bus created by NewWithOptions
+type Option func(*eventBus)
+
+// WithDefaultQueueSize sets the size of the queue used to pass messages to async subscribers
+func WithDefaultQueueSize(size int) Option {
+	return func(e *eventBus) {
+		e.queueSize = size
+	}
+}
+
+// WithTenantExtractor sets the function used to extract the tenant from the publish context. Messages are routed to
+// handlers registered with SubscribeTenant for the extracted tenant
+func WithTenantExtractor(fn func(ctx context.Context) string) Option {
+	return func(e *eventBus) {
+		e.tenantExtractor = fn
+	}
+}
+
+// WithAsyncLockContext sets the function used to derive the context passed to async handlers from the publish
+// context. Async handlers run after the publisher has returned, so the publish context may already be cancelled when
+// the handler acquires resources such as distributed locks. For example
+//
+//	bus.WithAsyncLockContext(func(parent context.Context) context.Context {
+//	   return context.Background()
+//	})
+//
+// Sync handlers receive the publish context unchanged.
+func WithAsyncLockContext(fn func(parent context.Context) context.Context) Option {
+	return func(e *eventBus) {
+		e.asyncLockContext = fn
+	}
+}
+
+// WithErrorMapper sets fn to transform the errors returned by handlers, for example to map sql.ErrNoRows to a domain
+// error. fn is called with the name of the handler each time it returns an error and the error it returns is used in
+// place of the handler error, by the publisher, error classifiers, loggers and dead letter handlers. If fn returns nil
+// the handler is treated as successful.
+func WithErrorMapper(fn func(handlerName string, err error) error) Option {
+	return func(e *eventBus) {
+		e.errorMapper = fn
+	}
+}
+
+// WithIsolatedContext passes handlers a context that has the values of the publish context but is not cancelled when
+// the publish context is, and has no deadline. Handlers that must complete once started, such as handlers that write
+// to several systems, are then not aborted part way through when the publisher gives up.
+func WithIsolatedContext() Option {
+	return func(e *eventBus) {
+		e.isolatedContext = true
+	}
+}
+
+// WithContextInheritKeys detaches the context passed to async handlers from the publish context, so async handlers are
+// not cancelled when the publish context is. The values of keys are copied from the publish context to the handler
+// context, other values are not available to async handlers. When combined with WithAsyncLockContext the values are
+// added to the context it returns. Sync handlers receive the publish context unchanged.
+func WithContextInheritKeys(keys ...interface{}) Option {
+	return func(e *eventBus) {
+		e.inheritKeys = append(e.inheritKeys, keys...)
+	}
+}
+
+// New create a new message bus. queueSize optionally sets the size of the queue used to pass messages to async
+// subscribers, see WithDefaultQueueSize. Use NewWithOptions to configure the bus further.
 func New(queueSize ...int) Bus {
-	handlers := newHandlers()
-	size := defaultAsyncHandlerQueueSize
 	if len(queueSize) > 0 {
-		size = queueSize[0]
+		return NewWithOptions(WithDefaultQueueSize(queueSize[0]))
+	}
+	return NewWithOptions()
+}
+
+// NewWithOptions creates a new message bus configured by opts
+func NewWithOptions(opts ...Option) Bus {
+	e := &eventBus{
+		handlers:      newHandlers(),
+		queueSize:     defaultAsyncHandlerQueueSize,
+		logger:        NoopLogger{},
+		done:          make(chan struct{}),
+		middleware:    &middlewares{},
+		panicRecovery: true,
 	}
-	return &eventBus{
-		handlers:  handlers,
-		queueSize: size,
+	for _, opt := range opts {
+		opt(e)
 	}
+	return e
 }
 
 type eventBus struct {
-	handlers  *handlers
-	queueSize int
+	handlers        *handlers
+	queueSize       int
+	tenantExtractor func(ctx context.Context) string
+	observersMu     sync.RWMutex
+	observers       []publishObserverFunc
+	lastObserverID  uint64
+	budgets         map[string]*executionBudget
+	adaptiveQueue   *adaptiveQueueConfig
+	deadLetterBus   Bus
+	// deadLetterHandlers are keyed by message type name
+	deadLetterHandlers map[string]DeadLetterHandler
+	deadLetterFallback DeadLetterHandler
+	deadLetters        deadLetters
+	dependencies       atomic.Value
+	lastHandlerID      uint64
+	argProvider        func(ctx context.Context, msg Message) []interface{}
+	timeouts           *escalatingTimeout
+	defaultRetry       RetryPolicy
+	// asyncLockContext derives the context passed to async handlers from the publish context
+	asyncLockContext func(parent context.Context) context.Context
+	// inheritKeys are the context keys copied from the publish context to the detached async handler context
+	inheritKeys []interface{}
+	errorLogger func(msgType, handlerName, file string, line int, err error)
+	errorMapper func(handlerName string, err error) error
+	logger      Logger
+	// asyncErrorHandler is called with the errors of async handlers
+	asyncErrorHandler func(ctx context.Context, msg Message, err error)
+	// subscriptionLimiter limits the rate of subscriptions, it is nil if the rate is unlimited
+	subscriptionLimiter *rate.Limiter
+	restartBackoff      *restartBackoff
+	filtersMu           sync.RWMutex
+	filters             []MessageFilter
+	stats               counters
+	isolatedContext     bool
+	duplicates          *duplicateCheck
+	// inflight is the number of publishes in progress
+	inflight int64
+	closed   atomic.Bool
+	// done is closed when the bus is closed
+	done          chan struct{}
+	middleware    *middlewares
+	panicRecovery bool
+	backpressure  BackpressurePolicy
+	tracer        Tracer
+	collector     Collector
+	verbosity     Verbosity
+	interfaces    interfaceTypes
+	// profileLabels counts the callers of EnableProfileLabels that have not yet disabled labelling
+	profileLabels atomic.Int32
+	// degraded records whether IsHealthy last reported the bus as unhealthy, so that only changes are logged
+	degraded atomic.Bool
 }
 
 type handler struct {
+	// id uniquely identifies the handler within the bus
+	id      uint64
 	Handler reflect.Value
 	isAsync bool
-	queue   chan []reflect.Value
+	queue   *asyncQueue
+	// coalescer debounces the messages of a handler subscribed with SubscribeCoalesced. It is nil for other handlers
+	coalescer *coalescer
+	// accept reports whether the message should be dispatched to the handler. All messages are accepted if nil. It is
+	// only called once the message is about to be dispatched, so that accepts recorded by SubscribeAtMostOnce and
+	// SubscribeDeduped are those of delivered messages
+	accept func(msg Message) bool
+	// source is the subscribed function, which differs from Handler when the function is wrapped
+	source interface{}
+	// name is the name of the subscribed function
+	name   string
+	tenant string
+	// codec decodes the messages of a handler subscribed with SubscribeRaw. It is nil for other handlers
+	codec Codec
+	// budget records the execution time of the handler. It is nil if the message type has no budget
+	budget *executionBudget
+	// rate measures the invocation rate of the handler
+	rate *rateMeter
+	// metrics records the duration and errors of each invocation
+	metrics *handlerMetrics
+	// msgType is the message type reported by Subscriptions. It is nil if the handler is called with the message type
+	// it is keyed by
+	msgType reflect.Type
+	// profileLabels is non-zero while the handler should be invoked with pprof labels
+	profileLabels *atomic.Int32
+	// middleware wraps each invocation of the handler
+	middleware *middlewares
+	// ownMiddleware wraps each invocation of the handler inside middleware
+	ownMiddleware []Middleware
+	// backpressure overrides the backpressure policy of the bus for the queue of an async handler
+	backpressure *BackpressurePolicy
+	// queueSize overrides the queue size of the bus for an async handler, see WithQueueSize
+	queueSize int
+	// retry configures the retries of the handler, see WithRetry. The handler is not retried if it is nil
+	retry *handlerRetry
+	// workers is the number of go routines consuming the queue of an async handler, see WithWorkers
+	workers int
+	// timeout bounds each invocation of the handler, see WithTimeout. There is no timeout if it is zero
+	timeout time.Duration
+	// recoverPanics converts panics of the handler into a *PanicError
+	recoverPanics bool
+	// tracer traces each invocation of the handler. It is nil if the bus has no tracer
+	tracer Tracer
+	// collector receives the metrics of the handler. It is nil if the bus has no collector
+	collector Collector
+	// logger logs each invocation of the handler. It is nil unless the bus is created with VerbosityPublishes
+	logger Logger
+	// priority orders the handler among the handlers of its message type, see WithPriority
+	priority int
+	// exclusive is set for a handler that must be the only handler of its message type, see WithSingleHandler
+	exclusive bool
+	// ephemeral is set for handlers subscribed internally for a single call, which are exempt from duplicate checks
+	ephemeral bool
 }
 
-func (e *eventBus) Subscribe(fn interface{}) error {
-	return e.subscribe(fn, false)
+// call invokes the handler with the given params
+func (h handler) call(params []reflect.Value) (result []reflect.Value) {
+	h.rate.Mark()
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		if h.budget != nil {
+			h.budget.Record(elapsed)
+		}
+		h.metrics.Record(elapsed, resultError(result))
+		if h.collector != nil {
+			h.collector.Handled(h.messageType().String(), h.name, elapsed, resultError(result))
+		}
+		if h.logger != nil {
+			h.logger.Debugf("handler %s handled %s in %s, error: %v", h.name, h.messageType(), elapsed, resultError(result))
+		}
+	}()
+	if h.tracer != nil {
+		var end func(err error)
+		params, end = h.startTrace(params)
+		defer func() {
+			end(resultError(result))
+		}()
+	}
+	if h.retry != nil {
+		return h.callWithRetry(params)
+	}
+	return h.callOnce(params)
+}
+
+// callOnce makes a single attempt to invoke the handler
+func (h handler) callOnce(params []reflect.Value) []reflect.Value {
+	if h.timeout > 0 {
+		return h.callWithDeadline(params)
+	}
+	return h.callRecover(params)
+}
+
+// callRecover invokes the handler through its middleware, recovering panics if enabled
+func (h handler) callRecover(params []reflect.Value) (result []reflect.Value) {
+	if h.recoverPanics {
+		defer h.recoverPanic(&result)
+	}
+	if mws := h.middleware.Get(); len(mws) > 0 || len(h.ownMiddleware) > 0 {
+		return h.invokeMiddleware(append(mws[:len(mws):len(mws)], h.ownMiddleware...), params)
+	}
+	return h.invoke(params)
+}
+
+func (h handler) invoke(params []reflect.Value) []reflect.Value {
+	if h.profileLabels == nil || h.profileLabels.Load() == 0 {
+		return h.Handler.Call(params)
+	}
+	return h.callLabelled(params)
+}
+
+// messageType returns the type of message handled by the handler
+func (h handler) messageType() reflect.Type {
+	if h.msgType != nil {
+		return h.msgType
+	}
+	return h.Handler.Type().In(1)
+}
+
+func (h handler) accepts(msg Message) bool {
+	return h.accept == nil || h.accept(msg)
+}
+
+// stop stops a handler that has been removed from the bus. Messages that have not been handled are discarded
+func (h handler) stop() {
+	if h.isAsync {
+		h.queue.Close()
+	}
+	if h.coalescer != nil {
+		h.coalescer.Stop()
+	}
+}
+
+func (e *eventBus) Subscribe(fn interface{}, opts ...SubscribeOption) error {
+	return e.subscribe(fn, false, opts)
 }
 
 func (e *eventBus) MustSubscribe(fn interface{}) {
-	if err := e.subscribe(fn, false); err != nil {
+	if err := e.subscribe(fn, false, nil); err != nil {
 		panic(err)
 	}
 }
 
-func (e *eventBus) SubscribeAsync(fn interface{}) error {
-	return e.subscribe(fn, true)
+func (e *eventBus) SubscribeAsync(fn interface{}, opts ...SubscribeOption) error {
+	return e.subscribe(fn, true, opts)
 }
 
 func (e *eventBus) MustSubscribeAsync(fn interface{}) {
-	if err := e.subscribe(fn, true); err != nil {
+	if err := e.subscribe(fn, true, nil); err != nil {
 		panic(err)
 	}
 }
 
-func (e *eventBus) subscribe(fn interface{}, isAsync bool) error {
+func (e *eventBus) SubscribeTenant(tenantID string, fn interface{}) error {
+	if tenantID == "" {
+		return errors.New("tenant must not be empty")
+	}
+	return e.subscribeHandler(fn, handler{tenant: tenantID})
+}
+
+func (e *eventBus) SubscribeAtMostOnce(fn interface{}, keyFn func(Message) string, window time.Duration) error {
+	processed := newTTLCache(window)
+	return e.subscribeHandler(fn, handler{
+		accept: func(msg Message) bool {
+			return processed.Add(keyFn(msg))
+		},
+	})
+}
+
+func (e *eventBus) SubscribeDeduped(fn interface{}, eq func(a, b Message) bool) error {
+	var mu sync.Mutex
+	var last Message
+	var seen bool
+	return e.subscribeHandler(fn, handler{
+		accept: func(msg Message) bool {
+			mu.Lock()
+			defer mu.Unlock()
+			duplicate := seen && eq(last, msg)
+			last, seen = msg, true
+			return !duplicate
+		},
+	})
+}
+
+func (e *eventBus) SubscribeWithAdvisoryTimeout(fn interface{}, d time.Duration, warn func(handlerName string, elapsed time.Duration)) error {
 	if err := validateHandler(fn); err != nil {
 		return err
 	}
-	handlerArgTypeName := reflect.TypeOf(fn).In(1).String()
-	handler := handler{
-		Handler: reflect.ValueOf(fn),
-		isAsync: isAsync,
+	name := handlerName(fn)
+	handlerFn := reflect.ValueOf(fn)
+	wrapped := reflect.MakeFunc(handlerFn.Type(), func(args []reflect.Value) []reflect.Value {
+		start := time.Now()
+		timer := time.AfterFunc(d, func() {
+			warn(name, time.Since(start))
+		})
+		defer timer.Stop()
+		return handlerFn.Call(args)
+	})
+	return e.subscribeHandler(wrapped.Interface(), handler{source: fn})
+}
+
+func (e *eventBus) SubscribeWithJitter(fn interface{}, maxJitter time.Duration) error {
+	if err := validateHandler(fn); err != nil {
+		return err
 	}
-	if isAsync {
-		handler.queue = make(chan []reflect.Value, defaultAsyncHandlerQueueSize)
-		go func() {
-			for params := range handler.queue {
-				handler.Handler.Call(params)
+	handlerFn := reflect.ValueOf(fn)
+	wrapped := reflect.MakeFunc(handlerFn.Type(), func(args []reflect.Value) []reflect.Value {
+		if maxJitter > 0 {
+			time.Sleep(time.Duration(rand.Int63n(int64(maxJitter))))
+		}
+		return handlerFn.Call(args)
+	})
+	return e.subscribeHandler(wrapped.Interface(), handler{isAsync: true, source: fn})
+}
+
+func (e *eventBus) subscribe(fn interface{}, isAsync bool, opts []SubscribeOption) error {
+	return e.subscribeHandler(fn, withOptions(handler{isAsync: isAsync}, opts))
+}
+
+// withOptions returns the handler configured by opts
+func withOptions(handler handler, opts []SubscribeOption) handler {
+	for _, opt := range opts {
+		opt(&handler)
+	}
+	return handler
+}
+
+// subscribeHandler registers fn using the settings of the given handler
+func (e *eventBus) subscribeHandler(fn interface{}, handler handler) error {
+	_, err := e.subscribeHandle(fn, handler)
+	return err
+}
+
+// subscribeHandle registers fn using the settings of the given handler and returns a handle to the subscription
+func (e *eventBus) subscribeHandle(fn interface{}, handler handler) (*subscription, error) {
+	return e.subscribeHandleCtx(context.Background(), fn, handler)
+}
+
+// subscribeHandleCtx registers fn once the subscription rate limit allows it, or returns ctx.Err() if ctx is done first
+func (e *eventBus) subscribeHandleCtx(ctx context.Context, fn interface{}, handler handler) (*subscription, error) {
+	if err := validateHandler(fn); err != nil {
+		return nil, err
+	}
+	if err := e.waitSubscription(ctx); err != nil {
+		return nil, err
+	}
+	if e.closed.Load() {
+		return nil, ErrBusClosed
+	}
+	handler = e.newHandler(fn, handler)
+	key := handlerKey(handler.tenant, reflect.TypeOf(fn).In(1).String())
+	if existing, err := e.handlers.AddChecked(key, handler, e.duplicates != nil); err != nil {
+		handler.stop()
+		if err != ErrAlreadySubscribed || !e.duplicates.ignore {
+			return nil, fmt.Errorf("%w: %s for '%s'", err, handler.name, key)
+		}
+		handler = existing
+	} else {
+		e.logSubscription(handler, key)
+		if msgType := handler.messageType(); msgType.Kind() == reflect.Interface && handler.tenant == "" {
+			e.interfaces.Add(msgType)
+		}
+	}
+	return &subscription{bus: e, key: key, id: handler.id, rate: handler.rate, metrics: handler.metrics, queue: handler.queue}, nil
+}
+
+// newHandler completes the settings of the given handler for fn and starts the async handler go routine
+func (e *eventBus) newHandler(fn interface{}, handler handler) handler {
+	msgTypeName := reflect.TypeOf(fn).In(1).String()
+	handler.id = atomic.AddUint64(&e.lastHandlerID, 1)
+	handler.Handler = reflect.ValueOf(fn)
+	handler.budget = e.budgets[msgTypeName]
+	handler.rate = newRateMeter()
+	handler.metrics = &handlerMetrics{}
+	handler.profileLabels = &e.profileLabels
+	handler.middleware = e.middleware
+	handler.tracer = e.tracer
+	handler.collector = e.collector
+	if e.verbosity >= VerbosityPublishes {
+		handler.logger = e.logger
+	}
+	handler.recoverPanics = e.panicRecovery || (handler.isAsync && e.restartBackoff != nil)
+	if handler.source == nil {
+		handler.source = fn
+	}
+	if handler.name == "" {
+		handler.name = handlerName(handler.source)
+	}
+	if handler.isAsync {
+		policy := e.backpressure
+		if handler.backpressure != nil {
+			policy = *handler.backpressure
+		}
+		if handler.queueSize > 0 {
+			handler.queue = newAsyncQueue(handler.queueSize, policy)
+		} else if e.adaptiveQueue != nil {
+			handler.queue = newAsyncQueue(e.adaptiveQueue.minSize, policy)
+			go e.adaptiveQueue.monitor(handler.queue)
+		} else {
+			handler.queue = newAsyncQueue(e.queueSize, policy)
+		}
+		for i := 0; i < handler.workers || i == 0; i++ {
+			go handler.queue.Consume(e.asyncWorker(handler))
+		}
+	}
+	if handler.coalescer != nil {
+		handler.coalescer.handler = handler
+	}
+	return handler
+}
+
+func (e *eventBus) ReplaceAllHandlers(msgType interface{}, fns ...interface{}) error {
+	t := reflect.TypeOf(msgType)
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Interface {
+		t = t.Elem()
+	}
+	msgTypeName := t.String()
+	for _, fn := range fns {
+		if err := validateHandler(fn); err != nil {
+			return err
+		}
+		if handlerArgTypeName := reflect.TypeOf(fn).In(1).String(); handlerArgTypeName != msgTypeName {
+			return fmt.Errorf("handler for '%s' cannot replace handlers for '%s'", handlerArgTypeName, msgTypeName)
+		}
+	}
+	for range fns {
+		if err := e.waitSubscription(context.Background()); err != nil {
+			return err
+		}
+	}
+	if e.closed.Load() {
+		return ErrBusClosed
+	}
+
+	var replacements []handler
+	for _, fn := range fns {
+		h := e.newHandler(fn, handler{})
+		if _, err := checkConflict(replacements, h, e.duplicates != nil); err != nil {
+			h.stop()
+			if err == ErrAlreadySubscribed && e.duplicates.ignore {
+				continue
 			}
-		}()
+			for _, replacement := range replacements {
+				replacement.stop()
+			}
+			return fmt.Errorf("%w: %s for '%s'", err, h.name, msgTypeName)
+		}
+		replacements = insertByPriority(replacements, h)
 	}
-	e.handlers.Add(handlerArgTypeName, handler)
-	return nil
+	replaced := e.handlers.Replace(msgTypeName, replacements)
+	for _, h := range replacements {
+		e.logSubscription(h, msgTypeName)
+	}
+	if t.Kind() == reflect.Interface && len(replacements) > 0 {
+		e.interfaces.Add(t)
+	}
+
+	var err error
+	for _, handler := range replaced {
+		if handler.isAsync && !handler.queue.Drain(defaultDrainTimeout) && err == nil {
+			err = fmt.Errorf("timed out draining async handler '%s'", handler.name)
+		}
+		handler.stop()
+	}
+	return err
+}
+
+func (e *eventBus) Subscriptions() []SubscriptionInfo {
+	var subscriptions []SubscriptionInfo
+	for _, key := range e.handlers.Keys() {
+		handlers, _ := e.handlers.Get(key)
+		kind, name := parseHandlerKey(key)
+		for _, handler := range handlers {
+			info := SubscriptionInfo{
+				MessageType: handler.messageType(),
+				HandlerName: handler.name,
+				Async:       handler.isAsync,
+				Tenant:      handler.tenant,
+				Kind:        kind,
+			}
+			switch kind {
+			case KindTopic:
+				info.Topic = name
+			case KindRaw:
+				info.RawMessageType = name
+				info.Codec = handler.codec
+			}
+			subscriptions = append(subscriptions, info)
+		}
+	}
+	return subscriptions
 }
 
 func (e *eventBus) Publish(ctx context.Context, msg Message) error {
-	msgTypeName := reflect.TypeOf(msg).String()
-	_, ok := e.handlers.Get(msgTypeName)
+	return e.publish(ctx, msg, stopOnError)
+}
+
+func (e *eventBus) PublishSync(ctx context.Context, msg Message) error {
+	msg, ok := e.filter(ctx, msg)
 	if !ok {
+		return nil
+	}
+	return e.publishKey(ctx, reflect.TypeOf(msg).String(), msg, stopOnError, publishSyncAll)
+}
+
+func (e *eventBus) PublishOptional(ctx context.Context, msg Message) error {
+	msg, ok := e.filter(ctx, msg)
+	if !ok {
+		return nil
+	}
+	return e.publishKey(ctx, reflect.TypeOf(msg).String(), msg, stopOnError, publishOptional)
+}
+
+func (e *eventBus) PublishAsync(ctx context.Context, msg Message) <-chan error {
+	result := make(chan error, 1)
+	if !e.enter() {
+		result <- ErrBusClosed
+		close(result)
+		return result
+	}
+	go func() {
+		defer e.exit()
+		defer close(result)
+		msg, ok := e.filter(ctx, msg)
+		if !ok {
+			result <- nil
+			return
+		}
+		result <- e.publishKey(ctx, reflect.TypeOf(msg).String(), msg, stopOnError, publishEntered)
+	}()
+	return result
+}
+
+func (e *eventBus) PublishBatch(ctx context.Context, msgs ...Message) error {
+	if !e.enter() {
+		return ErrBusClosed
+	}
+	defer e.exit()
+	var errs []error
+	for i, msg := range msgs {
+		if err := ctx.Err(); err != nil {
+			if len(errs) == 0 || !errors.Is(errs[len(errs)-1], err) {
+				errs = append(errs, err)
+			}
+			return errors.Join(errs...)
+		}
+		msg, ok := e.filter(ctx, msg)
+		if !ok {
+			continue
+		}
+		if err := e.publishKey(ctx, reflect.TypeOf(msg).String(), msg, stopOnError, publishEntered); err != nil {
+			errs = append(errs, fmt.Errorf("message %d %T: %w", i, msg, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (e *eventBus) PublishWithClassifier(ctx context.Context, msg Message, classify func(err error) ErrorAction) error {
+	return e.publish(ctx, msg, classify)
+}
+
+func stopOnError(error) ErrorAction {
+	return ActionStop
+}
+
+func (e *eventBus) publish(ctx context.Context, msg Message, classify func(err error) ErrorAction) error {
+	msg, ok := e.filter(ctx, msg)
+	if !ok {
+		return nil
+	}
+	return e.publishKey(ctx, reflect.TypeOf(msg).String(), msg, classify, publishDefault)
+}
+
+// publishMode modifies how publishKey dispatches a message
+type publishMode int
+
+// publishDefault queues the message for async handlers and calls sync handlers
+const publishDefault publishMode = 0
+
+const (
+	// publishSyncAll calls async handlers synchronously along with the sync handlers
+	publishSyncAll publishMode = 1 << iota
+	// publishOptional treats a message without handlers as handled
+	publishOptional
+	// publishEntered is set when the caller has already called enter, so the publish is accepted even if the bus is
+	// closed before it starts
+	publishEntered
+)
+
+// publishKey dispatches msg to the handlers subscribed under msgTypeName as described by mode
+func (e *eventBus) publishKey(ctx context.Context, msgTypeName string, msg Message, classify func(err error) ErrorAction, mode publishMode) (err error) {
+	if mode&publishEntered == 0 {
+		if !e.enter() {
+			return ErrBusClosed
+		}
+		defer e.exit()
+	}
+	e.stats.published.Add(1)
+	if e.collector != nil {
+		e.collector.Published(msgTypeName)
+	}
+	if e.verbosity >= VerbosityPublishes {
+		e.logger.Debugf("publishing %s", msgTypeName)
+	}
+	defer func() {
+		if err != nil {
+			e.stats.errors.Add(1)
+		}
+	}()
+	e.notifyObservers(msgTypeName, msg)
+	if e.tracer != nil {
+		var end func(err error)
+		ctx, end = e.tracer.StartPublish(ctx, msgTypeName, msg)
+		defer func() {
+			end(err)
+		}()
+	}
+	if e.isolatedContext {
+		ctx = context.WithoutCancel(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if budget, ok := e.budgets[msgTypeName]; ok && budget.Exceeded() {
+		e.logger.Warnf("execution budget for %s is exceeded, message rejected", msgTypeName)
+		return ErrBudgetExceeded
+	}
+	msgTypeName, handlers, ok := e.lookupHandlers(ctx, msgTypeName, msg)
+	if !ok {
+		e.logger.Debugf("no handler found for %s", msgTypeName)
+		if mode&publishOptional != 0 {
+			return nil
+		}
+		e.deadLetter(ctx, msg, ErrHandlerNotFound, 0)
 		return ErrHandlerNotFound
 	}
 
@@ -144,28 +1018,54 @@ func (e *eventBus) Publish(ctx context.Context, msg Message) error {
 	params = append(params, reflect.ValueOf(ctx))
 	params = append(params, reflect.ValueOf(msg))
 
-	// dispatch async handlers first
-	for messageHandlers := range e.handlers.Iter() {
-		if messageHandlers.Key == msgTypeName {
-			for _, handler := range messageHandlers.Value {
-				if handler.isAsync {
-					handler.queue <- params
-				}
+	asyncParams := params
+	if e.asyncLockContext != nil || e.inheritKeys != nil {
+		asyncParams = []reflect.Value{reflect.ValueOf(e.asyncContext(ctx)), reflect.ValueOf(msg)}
+	}
+	if e.tracer != nil {
+		asyncParams = []reflect.Value{reflect.ValueOf(e.traceAsync(ctx, asyncParams[0].Interface().(context.Context))), reflect.ValueOf(msg)}
+	}
+
+	syncAll := mode&publishSyncAll != 0
+	// dispatch async handlers first. The handlers are read once so that every handler is dispatched from the same set
+	// when handlers are replaced concurrently. Dispatch stops once the publish context is done
+	for _, handler := range handlers {
+		if handler.isAsync && !syncAll {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !handler.accepts(msg) {
+				continue
+			}
+			err := handler.queue.Push(e.handlerParams(handler, asyncParams))
+			if e.collector != nil {
+				e.reportQueue(handler, err)
+			}
+			switch err {
+			case errMessageDropped:
+				e.logger.Warnf("queue of async handler %s is full, dropped %s", handler.name, msgTypeName)
+			case ErrQueueFull:
+				return fmt.Errorf("%w: %s", ErrQueueFull, handler.name)
 			}
 		}
 	}
 
-	// handle sync handlers. If a handler errors we end the chain
-	for messageHandlers := range e.handlers.Iter() {
-		if messageHandlers.Key == msgTypeName {
-			for _, handler := range messageHandlers.Value {
-				isSync := !handler.isAsync
-				if isSync {
-					result := handler.Handler.Call(params)
-					if err := result[0].Interface(); err != nil {
-						return err.(error)
-					}
-				}
+	// handle sync handlers. The classifier decides whether a handler error ends the chain
+	for _, handler := range handlers {
+		isSync := !handler.isAsync || syncAll
+		if isSync {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if !handler.accepts(msg) {
+				continue
+			}
+			if handler.coalescer != nil {
+				handler.coalescer.push(e.handlerParams(handler, params))
+				continue
+			}
+			if err := e.callSync(handler, e.handlerParams(handler, params), classify); err != nil {
+				return err
 			}
 		}
 	}
@@ -173,6 +1073,193 @@ func (e *eventBus) Publish(ctx context.Context, msg Message) error {
 	return nil
 }
 
+// lookupHandlers returns the handlers that msg published under msgTypeName with ctx is dispatched to, along with the
+// key they are found under, which is the tenant key if the tenant of ctx has handlers for msgTypeName
+func (e *eventBus) lookupHandlers(ctx context.Context, msgTypeName string, msg Message) (string, []handler, bool) {
+	// messages published by type are also dispatched to the handlers of the interfaces they implement
+	isTypeKey := msgTypeName == reflect.TypeOf(msg).String()
+	if e.tenantExtractor != nil {
+		if tenantID := e.tenantExtractor(ctx); tenantID != "" {
+			if _, ok := e.handlers.Get(handlerKey(tenantID, msgTypeName)); ok {
+				msgTypeName = handlerKey(tenantID, msgTypeName)
+			}
+		}
+	}
+	if topic, isTopic := strings.CutPrefix(msgTypeName, topicKeyPrefix); isTopic {
+		handlers, ok := e.topicHandlers(topic)
+		return msgTypeName, handlers, ok
+	}
+	handlers, ok := e.handlers.Get(msgTypeName)
+	if isTypeKey {
+		handlers = e.withInterfaceHandlers(handlers, reflect.TypeOf(msg))
+		ok = len(handlers) > 0
+	}
+	return msgTypeName, handlers, ok
+}
+
+// mapError returns the error of a handler call transformed by the error mapper
+func (e *eventBus) mapError(handler handler, err error) error {
+	if err == nil || e.errorMapper == nil {
+		return err
+	}
+	return e.errorMapper(handler.name, err)
+}
+
+// handleAsync calls an async handler with params taken from its queue and returns the error returned by the handler
+// before it is mapped
+func (e *eventBus) handleAsync(handler handler, params []reflect.Value) error {
+	if e.collector != nil {
+		e.reportQueue(handler, nil)
+	}
+	handlerErr := resultError(handler.call(params))
+	if err := e.mapError(handler, handlerErr); err != nil {
+		e.stats.errors.Add(1)
+		e.logHandlerError(handler, err)
+		e.logger.Errorf("async handler %s failed to handle %s: %v", handler.name, handler.messageType(), err)
+		ctx, msg := params[0].Interface().(context.Context), params[1].Interface()
+		if e.asyncErrorHandler != nil {
+			e.asyncErrorHandler(ctx, msg, err)
+		}
+		attempts := 1
+		if handler.retry != nil && handler.retry.maxAttempts > 1 {
+			attempts = handler.retry.maxAttempts
+		}
+		e.deadLetter(ctx, msg, err, attempts)
+	}
+	return handlerErr
+}
+
+// asyncContext returns the context passed to async handlers for a message published with ctx
+func (e *eventBus) asyncContext(ctx context.Context) context.Context {
+	if e.inheritKeys == nil {
+		return e.asyncLockContext(ctx)
+	}
+	asyncCtx := context.Background()
+	if e.asyncLockContext != nil {
+		asyncCtx = e.asyncLockContext(ctx)
+	}
+	for _, key := range e.inheritKeys {
+		if value := ctx.Value(key); value != nil {
+			asyncCtx = context.WithValue(asyncCtx, key, value)
+		}
+	}
+	return asyncCtx
+}
+
+// callSync invokes a sync handler and returns the handler error if it should end the handler chain
+func (e *eventBus) callSync(handler handler, params []reflect.Value, classify func(err error) ErrorAction) error {
+	ctx := params[0].Interface().(context.Context)
+	policy := e.retryPolicy(ctx)
+	if handler.retry != nil {
+		// the handler retries itself
+		policy = RetryPolicy{}
+	}
+	for attempt := 0; ; attempt++ {
+		err := e.mapError(handler, e.callWithTimeout(handler, params, attempt))
+		if err == nil {
+			return nil
+		}
+		e.logHandlerError(handler, err)
+		switch classify(err) {
+		case ActionContinue:
+			return nil
+		case ActionDeadLetter:
+			e.deadLetter(ctx, params[1].Interface(), err, attempt+1)
+			return nil
+		case ActionRetry:
+			maxAttempts := policy.MaxAttempts
+			if maxAttempts < 2 {
+				maxAttempts = defaultClassifierAttempts
+			}
+			if attempt+1 >= maxAttempts || !policy.wait(ctx) {
+				return err
+			}
+		default:
+			if attempt+1 >= policy.MaxAttempts || !policy.wait(ctx) {
+				return err
+			}
+		}
+	}
+}
+
+// publishObserverFunc is a function registered with observePublish
+type publishObserverFunc struct {
+	id uint64
+	fn func(msgTypeName string, msg Message)
+}
+
+// observePublish registers fn to be called with the message type name and the message each time a message is
+// published. It returns the id that unregisters fn with unobservePublish
+func (e *eventBus) observePublish(fn func(msgTypeName string, msg Message)) uint64 {
+	e.observersMu.Lock()
+	defer e.observersMu.Unlock()
+	e.lastObserverID++
+	e.observers = append(e.observers, publishObserverFunc{id: e.lastObserverID, fn: fn})
+	return e.lastObserverID
+}
+
+// unobservePublish unregisters the function registered with observePublish under id. Once it returns the function is
+// no longer called
+func (e *eventBus) unobservePublish(id uint64) {
+	e.observersMu.Lock()
+	defer e.observersMu.Unlock()
+	for i, observer := range e.observers {
+		if observer.id == id {
+			e.observers = append(e.observers[:i:i], e.observers[i+1:]...)
+			return
+		}
+	}
+}
+
+func (e *eventBus) notifyObservers(msgTypeName string, msg Message) {
+	e.observersMu.RLock()
+	defer e.observersMu.RUnlock()
+	for _, observer := range e.observers {
+		observer.fn(msgTypeName, msg)
+	}
+}
+
+// handlerKey returns the key used to store handlers. Tenant handlers are keyed separately from global handlers
+func handlerKey(tenantID string, msgTypeName string) string {
+	if tenantID == "" {
+		return msgTypeName
+	}
+	return tenantID + "/" + msgTypeName
+}
+
+// parseHandlerKey returns the kind of the handlers stored under key and the rest of the key, which is the topic
+// pattern of topic handlers and the message type name of the other kinds
+func parseHandlerKey(key string) (SubscriptionKind, string) {
+	if pattern, ok := strings.CutPrefix(key, topicKeyPrefix); ok {
+		return KindTopic, pattern
+	}
+	if msgTypeName, ok := strings.CutPrefix(key, rawKeyPrefix); ok {
+		return KindRaw, msgTypeName
+	}
+	if msgTypeName, ok := strings.CutPrefix(key, requestKeyPrefix); ok {
+		return KindRequest, msgTypeName
+	}
+	return KindType, key
+}
+
+// publishedType returns the kind of the publish of msg under the handler key, the name of the published message type
+// and the topic it was published to. The message type name of raw publishes is the name given to PublishRaw
+func publishedType(key string, msg Message) (kind SubscriptionKind, msgTypeName string, topic string) {
+	kind, name := parseHandlerKey(key)
+	if kind == KindTopic {
+		return kind, reflect.TypeOf(msg).String(), name
+	}
+	return kind, name, ""
+}
+
+// handlerName returns the fully qualified name of the handler function
+func handlerName(fn interface{}) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		return f.Name()
+	}
+	return reflect.TypeOf(fn).String()
+}
+
 func validateHandler(fn interface{}) error {
 	typeOf := reflect.TypeOf(fn)
 	if typeOf.Kind() != reflect.Func {
@@ -184,6 +1271,16 @@ func validateHandler(fn interface{}) error {
 	if typeOf.In(0).String() != "context.Context" {
 		return errors.New("first argument must be context.Context")
 	}
+	msgType := typeOf.In(1)
+	if msgType.Kind() == reflect.Interface {
+		return nil
+	}
+	if msgType.Kind() == reflect.Ptr {
+		msgType = msgType.Elem()
+	}
+	if msgType.Kind() != reflect.Struct {
+		return fmt.Errorf("%w: '%s'", ErrNonStructMessage, typeOf.In(1))
+	}
 	return nil
 }
 
@@ -192,11 +1289,6 @@ type handlers struct {
 	items map[string][]handler
 }
 
-type handlerItem struct {
-	Key   string
-	Value []handler
-}
-
 func newHandlers() *handlers {
 	cm := &handlers{
 		items: make(map[string][]handler),
@@ -207,28 +1299,127 @@ func newHandlers() *handlers {
 func (cm *handlers) Add(key string, value handler) {
 	cm.Lock()
 	defer cm.Unlock()
-	cm.items[key] = append(cm.items[key], value)
+	cm.items[key] = insertByPriority(cm.items[key], value)
 }
 
-func (cm *handlers) Get(key string) ([]handler, bool) {
+// AddChecked adds value unless it conflicts with the handlers registered for key. If unique is true and a handler for
+// the same function is registered, ErrAlreadySubscribed is returned along with the existing handler. ErrMultipleHandlers
+// is returned if value or a registered handler is exclusive, see WithSingleHandler
+func (cm *handlers) AddChecked(key string, value handler, unique bool) (handler, error) {
+	cm.Lock()
+	defer cm.Unlock()
+	items := cm.items[key]
+	if existing, err := checkConflict(items, value, unique); err != nil {
+		return existing, err
+	}
+	cm.items[key] = insertByPriority(items, value)
+	return value, nil
+}
+
+// checkConflict returns the error AddChecked returns if value conflicts with items
+func checkConflict(items []handler, value handler, unique bool) (handler, error) {
+	if unique {
+		for _, h := range items {
+			if sameFunc(h, value) {
+				return h, ErrAlreadySubscribed
+			}
+		}
+	}
+	if len(items) > 0 && (value.exclusive || items[0].exclusive) {
+		return handler{}, ErrMultipleHandlers
+	}
+	return handler{}, nil
+}
+
+// insertByPriority returns a copy of items with value inserted after the handlers of the same or a higher priority.
+// items is not modified because it may be in use by a publish
+func insertByPriority(items []handler, value handler) []handler {
+	i := len(items)
+	for i > 0 && items[i-1].priority < value.priority {
+		i--
+	}
+	inserted := make([]handler, 0, len(items)+1)
+	inserted = append(inserted, items[:i]...)
+	inserted = append(inserted, value)
+	return append(inserted, items[i:]...)
+}
+
+// Replace sets the handlers for key and returns the handlers that were replaced
+func (cm *handlers) Replace(key string, values []handler) []handler {
+	cm.Lock()
+	defer cm.Unlock()
+	replaced := cm.items[key]
+	if len(values) == 0 {
+		delete(cm.items, key)
+	} else {
+		cm.items[key] = values
+	}
+	return replaced
+}
+
+// RemoveFunc removes the handlers subscribed with fn from every key and returns them
+func (cm *handlers) RemoveFunc(fn interface{}) []handler {
+	cm.Lock()
+	defer cm.Unlock()
+	target := handler{source: fn}
+	var removed []handler
+	for key, items := range cm.items {
+		remaining := make([]handler, 0, len(items))
+		for _, h := range items {
+			if sameFunc(h, target) {
+				removed = append(removed, h)
+			} else {
+				remaining = append(remaining, h)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(cm.items, key)
+		} else if len(remaining) < len(items) {
+			cm.items[key] = remaining
+		}
+	}
+	return removed
+}
+
+// Remove removes the handler with the given id and reports whether it was found
+func (cm *handlers) Remove(key string, id uint64) (handler, bool) {
 	cm.Lock()
 	defer cm.Unlock()
+	for i, h := range cm.items[key] {
+		if h.id == id {
+			remaining := make([]handler, 0, len(cm.items[key])-1)
+			remaining = append(remaining, cm.items[key][:i]...)
+			remaining = append(remaining, cm.items[key][i+1:]...)
+			if len(remaining) == 0 {
+				delete(cm.items, key)
+			} else {
+				cm.items[key] = remaining
+			}
+			return h, true
+		}
+	}
+	return handler{}, false
+}
+
+func (cm *handlers) Get(key string) ([]handler, bool) {
+	cm.RLock()
+	defer cm.RUnlock()
 	value, ok := cm.items[key]
 	return value, ok
 }
 
-func (cm *handlers) Iter() <-chan handlerItem {
-	c := make(chan handlerItem)
-	f := func() {
-		cm.Lock()
-		defer cm.Unlock()
-		for k, v := range cm.items {
-			c <- handlerItem{k, v}
-		}
-		close(c)
+// Keys returns the handler keys in sorted order
+func (cm *handlers) Keys() []string {
+	cm.RLock()
+	defer cm.RUnlock()
+	keys := make([]string, 0, len(cm.items))
+	for k := range cm.items {
+		keys = append(keys, k)
 	}
-	go f()
-	return c
+	sort.Strings(keys)
+	return keys
 }
 
 const defaultAsyncHandlerQueueSize = 1000
+
+const defaultDrainTimeout = 5 * time.Second
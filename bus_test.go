@@ -7,7 +7,9 @@ import (
 	"github.com/steinfletcher/bus"
 	"github.com/stretchr/testify/assert"
 	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -68,6 +70,10 @@ func TestBus_InvalidHandler(t *testing.T) {
 			handlerFunc: func(a string, b string) {},
 			errContains: "first argument must be context.Context",
 		},
+		"message must be a struct": {
+			handlerFunc: func(ctx context.Context, msg *string) {},
+			errContains: "message must be a struct: '*string'",
+		},
 		"success": {
 			handlerFunc: func(ctx context.Context, arg *GetUserQuery) {},
 		},
@@ -86,6 +92,17 @@ func TestBus_InvalidHandler(t *testing.T) {
 	}
 }
 
+func TestBus_Subscribe_NonStructMessage(t *testing.T) {
+	b := bus.New()
+
+	err := b.Subscribe(func(ctx context.Context, msg *string) error {
+		return nil
+	})
+
+	assert.True(t, errors.Is(err, bus.ErrNonStructMessage))
+	assert.Empty(t, b.Subscriptions())
+}
+
 func TestBus_MultipleSubscribers(t *testing.T) {
 	b := bus.New()
 	var handler1Invoked bool
@@ -273,6 +290,781 @@ func TestBus_MultipleSyncHandlers_PreventsFutureHandlersOnError(t *testing.T) {
 	assert.False(t, handler2Invoked)
 }
 
+type tenantKey struct{}
+
+func TestBus_SubscribeTenant(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithTenantExtractor(func(ctx context.Context) string {
+		tenant, _ := ctx.Value(tenantKey{}).(string)
+		return tenant
+	}))
+	var invoked []string
+
+	_ = b.SubscribeTenant("tenant-A", func(ctx context.Context, query *GetUserQuery) error {
+		invoked = append(invoked, "tenant-A")
+		return nil
+	})
+	_ = b.SubscribeTenant("tenant-B", func(ctx context.Context, query *GetUserQuery) error {
+		invoked = append(invoked, "tenant-B")
+		return nil
+	})
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		invoked = append(invoked, "global")
+		return nil
+	})
+
+	for _, tenant := range []string{"tenant-A", "tenant-B", "tenant-C", ""} {
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		err := b.Publish(ctx, &GetUserQuery{ID: "1234"})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"tenant-A", "tenant-B", "global", "global"}, invoked)
+}
+
+func TestBus_SubscribeTenant_HandlerNotFound(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithTenantExtractor(func(ctx context.Context) string {
+		return "tenant-A"
+	}))
+	_ = b.SubscribeTenant("tenant-B", func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	})
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, bus.ErrHandlerNotFound, err)
+}
+
+func TestBus_SubscribeTenant_EmptyTenant(t *testing.T) {
+	b := bus.New()
+
+	err := b.SubscribeTenant("", func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	})
+
+	assert.EqualError(t, err, "tenant must not be empty")
+}
+
+func TestBus_SubscribeAtMostOnce(t *testing.T) {
+	b := bus.New()
+	var invoked int
+
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		invoked++
+		return nil
+	}
+	keyFn := func(msg bus.Message) string {
+		return msg.(*GetUserQuery).ID
+	}
+	_ = b.SubscribeAtMostOnce(handler, keyFn, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+		assert.NoError(t, err)
+	}
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "5678"})
+
+	assert.Equal(t, 2, invoked)
+}
+
+func TestBus_SubscribeAtMostOnce_CancelledBeforeDispatch(t *testing.T) {
+	b := bus.New()
+	var invoked int
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		cancel()
+		return nil
+	})
+	keyFn := func(msg bus.Message) string {
+		return msg.(*GetUserQuery).ID
+	}
+	_ = b.SubscribeAtMostOnce(func(ctx context.Context, query *GetUserQuery) error {
+		invoked++
+		return nil
+	}, keyFn, time.Minute)
+
+	assert.ErrorIs(t, b.Publish(ctx, &GetUserQuery{ID: "1234"}), context.Canceled)
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	assert.Equal(t, 1, invoked)
+}
+
+func TestBus_SubscribeAtMostOnce_WindowExpires(t *testing.T) {
+	b := bus.New()
+	var invoked int
+
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		invoked++
+		return nil
+	}
+	keyFn := func(msg bus.Message) string {
+		return msg.(*GetUserQuery).ID
+	}
+	_ = b.SubscribeAtMostOnce(handler, keyFn, 50*time.Millisecond)
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	time.Sleep(100 * time.Millisecond)
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, 2, invoked)
+}
+
+var (
+	errSkippable = errors.New("skippable")
+	errFatal     = errors.New("fatal")
+	errTransient = errors.New("transient")
+)
+
+func classifyTestErrors(err error) bus.ErrorAction {
+	switch err {
+	case errSkippable:
+		return bus.ActionContinue
+	case errTransient:
+		return bus.ActionRetry
+	default:
+		return bus.ActionStop
+	}
+}
+
+func TestBus_PublishWithClassifier(t *testing.T) {
+	tests := map[string]struct {
+		handler1Err     error
+		handler2Err     error
+		handler2Invoked bool
+		expectedErr     error
+	}{
+		"stop on first handler": {
+			handler1Err:     errFatal,
+			handler2Err:     errSkippable,
+			handler2Invoked: false,
+			expectedErr:     errFatal,
+		},
+		"continue after first handler": {
+			handler1Err:     errSkippable,
+			handler2Err:     errFatal,
+			handler2Invoked: true,
+			expectedErr:     errFatal,
+		},
+		"continue after both handlers": {
+			handler1Err:     errSkippable,
+			handler2Err:     errSkippable,
+			handler2Invoked: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := bus.New()
+			var handler2Invoked bool
+			_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+				return test.handler1Err
+			})
+			_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+				handler2Invoked = true
+				return test.handler2Err
+			})
+
+			err := b.PublishWithClassifier(context.Background(), &GetUserQuery{ID: "1234"}, classifyTestErrors)
+
+			assert.Equal(t, test.expectedErr, err)
+			assert.Equal(t, test.handler2Invoked, handler2Invoked)
+		})
+	}
+}
+
+func TestBus_PublishWithClassifier_Retry(t *testing.T) {
+	b := bus.New()
+	var attempts int
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		attempts++
+		if attempts < 3 {
+			return errTransient
+		}
+		return nil
+	})
+
+	err := b.PublishWithClassifier(context.Background(), &GetUserQuery{ID: "1234"}, classifyTestErrors)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestBus_PublishWithClassifier_RetryBounded(t *testing.T) {
+	b := bus.New()
+	var attempts int
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		attempts++
+		return errTransient
+	})
+	ctx := bus.WithRetryPolicy(context.Background(), bus.RetryPolicy{MaxAttempts: 5, Backoff: time.Millisecond})
+
+	assert.Equal(t, errTransient, b.PublishWithClassifier(ctx, &GetUserQuery{ID: "1234"}, classifyTestErrors))
+	assert.Equal(t, 5, attempts)
+
+	attempts = 0
+	assert.Equal(t, errTransient, b.PublishWithClassifier(context.Background(), &GetUserQuery{ID: "1234"}, classifyTestErrors))
+	assert.Equal(t, 3, attempts)
+}
+
+func TestBus_PublishWithClassifier_DeadLetter(t *testing.T) {
+	b := bus.New()
+	envelopes := make(chan bus.DeadLetterEnvelope, 1)
+	_ = b.SubscribeDeadLetter(func(ctx context.Context, envelope bus.DeadLetterEnvelope) {
+		envelopes <- envelope
+	})
+	var handler2Invoked bool
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return errFatal
+	})
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		handler2Invoked = true
+		return nil
+	})
+
+	err := b.PublishWithClassifier(context.Background(), &GetUserQuery{ID: "1234"}, func(err error) bus.ErrorAction {
+		return bus.ActionDeadLetter
+	})
+
+	assert.NoError(t, err)
+	assert.True(t, handler2Invoked)
+	envelope := <-envelopes
+	assert.Equal(t, errFatal, envelope.Reason)
+	assert.Equal(t, &GetUserQuery{ID: "1234"}, envelope.OriginalMessage)
+}
+
+func TestBus_SubscribeDeduped(t *testing.T) {
+	b := bus.New()
+	var received []string
+	_ = b.SubscribeDeduped(func(ctx context.Context, query *GetUserQuery) error {
+		received = append(received, query.ID)
+		return nil
+	}, func(a, b bus.Message) bool {
+		return a.(*GetUserQuery).ID == b.(*GetUserQuery).ID
+	})
+
+	for _, id := range []string{"1234", "1234", "1234", "5678", "1234"} {
+		assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: id}))
+	}
+
+	assert.Equal(t, []string{"1234", "5678", "1234"}, received)
+}
+
+func TestBus_SubscribeWithAdvisoryTimeout(t *testing.T) {
+	b := bus.New()
+	warned := make(chan time.Duration, 1)
+	var warnedHandler string
+
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		time.Sleep(100 * time.Millisecond)
+		return errors.New("slow failure")
+	}
+	warn := func(handlerName string, elapsed time.Duration) {
+		warnedHandler = handlerName
+		warned <- elapsed
+	}
+	_ = b.SubscribeWithAdvisoryTimeout(handler, 50*time.Millisecond, warn)
+
+	start := time.Now()
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	completed := time.Since(start)
+
+	assert.EqualError(t, err, "slow failure")
+	assert.GreaterOrEqual(t, completed, 100*time.Millisecond)
+	elapsed := <-warned
+	assert.GreaterOrEqual(t, elapsed, 50*time.Millisecond)
+	assert.Less(t, elapsed, 100*time.Millisecond)
+	assert.Contains(t, warnedHandler, "TestBus_SubscribeWithAdvisoryTimeout")
+}
+
+func TestBus_SubscribeWithAdvisoryTimeout_FastHandler(t *testing.T) {
+	b := bus.New()
+	var warned bool
+
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	}
+	_ = b.SubscribeWithAdvisoryTimeout(handler, 50*time.Millisecond, func(string, time.Duration) {
+		warned = true
+	})
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	time.Sleep(100 * time.Millisecond)
+
+	assert.NoError(t, err)
+	assert.False(t, warned)
+}
+
+func TestBus_PublishSync(t *testing.T) {
+	b := bus.New()
+	var completed atomic.Bool
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		time.Sleep(50 * time.Millisecond)
+		completed.Store(true)
+	})
+	start := time.Now()
+
+	err := b.PublishSync(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.True(t, completed.Load())
+}
+
+func TestBus_PublishSync_AsyncHandlerError(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) error {
+		return errors.New("user not found")
+	})
+
+	err := b.PublishSync(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestBus_PublishAsync(t *testing.T) {
+	b := bus.New()
+	release := make(chan struct{})
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		<-release
+		return errors.New("user not found")
+	})
+
+	result := b.PublishAsync(context.Background(), &GetUserQuery{ID: "1234"})
+	select {
+	case <-result:
+		t.Fatal("PublishAsync waited for the handler")
+	default:
+	}
+	close(release)
+
+	assert.EqualError(t, <-result, "user not found")
+	_, open := <-result
+	assert.False(t, open)
+}
+
+func TestBus_PublishAsync_Close(t *testing.T) {
+	b := bus.New()
+	var handled atomic.Bool
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		time.Sleep(20 * time.Millisecond)
+		handled.Store(true)
+		return nil
+	})
+
+	result := b.PublishAsync(context.Background(), &GetUserQuery{ID: "1234"})
+	assert.NoError(t, b.Close(context.Background()))
+
+	assert.True(t, handled.Load())
+	assert.NoError(t, <-result)
+	assert.Equal(t, bus.ErrBusClosed, <-b.PublishAsync(context.Background(), &GetUserQuery{ID: "1234"}))
+}
+
+func TestBus_PublishBatch(t *testing.T) {
+	b := bus.New()
+	var handled []string
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		handled = append(handled, query.ID)
+		if query.ID == "2" {
+			return errors.New("user not found")
+		}
+		return nil
+	})
+
+	err := b.PublishBatch(context.Background(), &GetUserQuery{ID: "1"}, &GetUserQuery{ID: "2"}, &UserResult{}, &GetUserQuery{ID: "3"})
+
+	assert.Equal(t, []string{"1", "2", "3"}, handled)
+	assert.True(t, errors.Is(err, bus.ErrHandlerNotFound))
+	assert.EqualError(t, err, "message 1 *bus_test.GetUserQuery: user not found\nmessage 2 *bus_test.UserResult: handler not found")
+}
+
+func TestBus_PublishBatch_ContextCancelled(t *testing.T) {
+	b := bus.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	var handled []string
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		handled = append(handled, query.ID)
+		if query.ID == "2" {
+			cancel()
+		}
+		return nil
+	})
+
+	err := b.PublishBatch(ctx, &GetUserQuery{ID: "1"}, &GetUserQuery{ID: "2"}, &GetUserQuery{ID: "3"}, &GetUserQuery{ID: "4"})
+
+	assert.Equal(t, []string{"1", "2"}, handled)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.EqualError(t, err, "context canceled")
+}
+
+func TestBus_PublishBatch_Empty(t *testing.T) {
+	assert.NoError(t, bus.New().PublishBatch(context.Background()))
+}
+
+func TestBus_PublishOptional(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDeadLetterQueue(10))
+
+	err := b.PublishOptional(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, bus.DrainDeadLetters(b))
+}
+
+func TestBus_PublishOptional_HandlerError(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return errors.New("user not found")
+	})
+
+	err := b.PublishOptional(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestBus_WithErrorMapper(t *testing.T) {
+	errNotFound := errors.New("not found")
+	var mappedHandler string
+	b := bus.NewWithOptions(bus.WithErrorMapper(func(handlerName string, err error) error {
+		mappedHandler = handlerName
+		if errors.Is(err, errSkippable) {
+			return errNotFound
+		}
+		return err
+	}))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		if query.ID == "1234" {
+			return errSkippable
+		}
+		return errFatal
+	})
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, errNotFound, err)
+	assert.Equal(t, "github.com/steinfletcher/bus_test.TestBus_WithErrorMapper.func2", mappedHandler)
+	assert.Equal(t, errFatal, b.Publish(context.Background(), &GetUserQuery{ID: "5678"}))
+}
+
+func TestBus_WithErrorMapper_SeenByClassifier(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithErrorMapper(func(handlerName string, err error) error {
+		return errSkippable
+	}))
+	var called bool
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return errFatal
+	})
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		called = true
+		return nil
+	})
+
+	err := b.PublishWithClassifier(context.Background(), &GetUserQuery{}, classifyTestErrors)
+
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func TestBus_SubscribeWithJitter(t *testing.T) {
+	b := bus.New()
+	completed := make(chan time.Duration, 10)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		_ = b.SubscribeWithJitter(func(ctx context.Context, query *GetUserQuery) {
+			completed <- time.Since(start)
+		}, 100*time.Millisecond)
+	}
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	var elapsed []time.Duration
+	for i := 0; i < 10; i++ {
+		elapsed = append(elapsed, <-completed)
+	}
+	sort.Slice(elapsed, func(i, j int) bool { return elapsed[i] < elapsed[j] })
+	assert.Less(t, elapsed[9], 200*time.Millisecond)
+	assert.Greater(t, elapsed[9]-elapsed[0], 10*time.Millisecond)
+}
+
+func getUserHandler(ctx context.Context, query *GetUserQuery) error {
+	return nil
+}
+
+func someCommandHandler(ctx context.Context, command SomeCommand) {}
+
+func TestBus_Subscriptions(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(getUserHandler)
+	_ = b.SubscribeAsync(someCommandHandler)
+	_ = b.SubscribeTenant("tenant-A", getUserHandler)
+
+	subscriptions := b.Subscriptions()
+
+	assert.Equal(t, []bus.SubscriptionInfo{
+		{
+			MessageType: reflect.TypeOf(&GetUserQuery{}),
+			HandlerName: "github.com/steinfletcher/bus_test.getUserHandler",
+		},
+		{
+			MessageType: reflect.TypeOf(SomeCommand{}),
+			HandlerName: "github.com/steinfletcher/bus_test.someCommandHandler",
+			Async:       true,
+		},
+		{
+			MessageType: reflect.TypeOf(&GetUserQuery{}),
+			HandlerName: "github.com/steinfletcher/bus_test.getUserHandler",
+			Tenant:      "tenant-A",
+		},
+	}, subscriptions)
+}
+
+func TestBus_Subscriptions_Kinds(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeTopic("users.*", getUserHandler)
+	_ = b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, getUserHandler)
+	_ = b.SubscribeRequest(func(ctx context.Context, query *GetUserQuery) (string, error) {
+		return query.ID, nil
+	})
+
+	subscriptions := b.Subscriptions()
+
+	assert.Len(t, subscriptions, 3)
+	assert.Equal(t, bus.SubscriptionInfo{
+		MessageType:    reflect.TypeOf(&GetUserQuery{}),
+		HandlerName:    "github.com/steinfletcher/bus_test.getUserHandler",
+		Kind:           bus.KindRaw,
+		RawMessageType: "GetUserQuery",
+		Codec:          bus.JSONCodec{},
+	}, subscriptions[0])
+	assert.Equal(t, bus.KindRequest, subscriptions[1].Kind)
+	assert.Equal(t, reflect.TypeOf(&GetUserQuery{}), subscriptions[1].MessageType)
+	assert.Equal(t, bus.SubscriptionInfo{
+		MessageType: reflect.TypeOf(&GetUserQuery{}),
+		HandlerName: "github.com/steinfletcher/bus_test.getUserHandler",
+		Kind:        bus.KindTopic,
+		Topic:       "users.*",
+	}, subscriptions[2])
+	assert.Equal(t, "request", bus.KindRequest.String())
+}
+
+type lockContextKey struct{}
+
+func TestBus_WithAsyncLockContext(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithAsyncLockContext(func(parent context.Context) context.Context {
+		return context.WithValue(context.Background(), lockContextKey{}, "lock")
+	}))
+	asyncCtx := make(chan context.Context, 1)
+	var syncCtx context.Context
+
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		syncCtx = ctx
+		return nil
+	})
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		asyncCtx <- ctx
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), "key", "value"))
+	err := b.Publish(ctx, &GetUserQuery{ID: "1234"})
+	cancel()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "value", syncCtx.Value("key"))
+	assert.Nil(t, syncCtx.Value(lockContextKey{}))
+	lockCtx := <-asyncCtx
+	assert.Equal(t, "lock", lockCtx.Value(lockContextKey{}))
+	assert.Nil(t, lockCtx.Value("key"))
+	assert.NoError(t, lockCtx.Err())
+}
+
+func TestBus_WithContextInheritKeys(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithContextInheritKeys(tenantKey{}))
+	asyncCtx := make(chan context.Context, 1)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		asyncCtx <- ctx
+	})
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), tenantKey{}, "acme"))
+	ctx = context.WithValue(ctx, lockContextKey{}, "lock")
+	err := b.Publish(ctx, &GetUserQuery{ID: "1234"})
+	cancel()
+
+	assert.NoError(t, err)
+	handlerCtx := <-asyncCtx
+	assert.Equal(t, "acme", handlerCtx.Value(tenantKey{}))
+	assert.Nil(t, handlerCtx.Value(lockContextKey{}))
+	assert.NoError(t, handlerCtx.Err())
+}
+
+func TestBus_WithContextInheritKeys_WithAsyncLockContext(t *testing.T) {
+	b := bus.NewWithOptions(
+		bus.WithContextInheritKeys(tenantKey{}),
+		bus.WithAsyncLockContext(func(parent context.Context) context.Context {
+			return context.WithValue(context.Background(), lockContextKey{}, "lock")
+		}),
+	)
+	asyncCtx := make(chan context.Context, 1)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		asyncCtx <- ctx
+	})
+
+	err := b.Publish(context.WithValue(context.Background(), tenantKey{}, "acme"), &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	handlerCtx := <-asyncCtx
+	assert.Equal(t, "acme", handlerCtx.Value(tenantKey{}))
+	assert.Equal(t, "lock", handlerCtx.Value(lockContextKey{}))
+}
+
+func TestBus_WithIsolatedContext(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithIsolatedContext())
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), tenantKey{}, "acme"))
+	var handlerErr error
+	var tenant interface{}
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		cancel()
+		handlerErr = ctx.Err()
+		tenant = ctx.Value(tenantKey{})
+		return nil
+	})
+
+	err := b.Publish(ctx, &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Error(t, ctx.Err())
+	assert.NoError(t, handlerErr)
+	assert.Equal(t, "acme", tenant)
+}
+
+func TestBus_ReplaceAllHandlers(t *testing.T) {
+	b := bus.New()
+	var mu sync.Mutex
+	invocations := make(map[string][]string)
+	record := func(set string) func(ctx context.Context, query *GetUserQuery) error {
+		return func(ctx context.Context, query *GetUserQuery) error {
+			mu.Lock()
+			defer mu.Unlock()
+			invocations[query.ID] = append(invocations[query.ID], set)
+			return nil
+		}
+	}
+	_ = b.Subscribe(record("old"))
+	_ = b.Subscribe(record("old"))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(publisher int) {
+			defer wg.Done()
+			for j := 0; j < 250; j++ {
+				_ = b.Publish(context.Background(), &GetUserQuery{ID: fmt.Sprintf("%d-%d", publisher, j)})
+			}
+		}(i)
+	}
+	err := b.ReplaceAllHandlers(&GetUserQuery{}, record("new"), record("new"))
+	wg.Wait()
+
+	assert.NoError(t, err)
+	assert.Len(t, invocations, 1000)
+	for id, sets := range invocations {
+		assert.Len(t, sets, 2, id)
+		assert.Equal(t, sets[0], sets[1], id)
+	}
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "after"})
+	assert.Equal(t, []string{"new", "new"}, invocations["after"])
+}
+
+func TestBus_ReplaceAllHandlers_DrainsAsyncHandlers(t *testing.T) {
+	b := bus.New()
+	var handled int32
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&handled, 1)
+	})
+	for i := 0; i < 5; i++ {
+		_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	}
+
+	err := b.ReplaceAllHandlers(&GetUserQuery{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), atomic.LoadInt32(&handled))
+	assert.Equal(t, bus.ErrHandlerNotFound, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+}
+
+func TestBus_ReplaceAllHandlers_WrongMessageType(t *testing.T) {
+	b := bus.New()
+
+	err := b.ReplaceAllHandlers(&GetUserQuery{}, func(ctx context.Context, command SomeCommand) error {
+		return nil
+	})
+
+	assert.EqualError(t, err, "handler for 'bus_test.SomeCommand' cannot replace handlers for '*bus_test.GetUserQuery'")
+}
+
+func TestBus_ReplaceAllHandlers_Closed(t *testing.T) {
+	b := bus.New()
+	assert.NoError(t, b.Close(context.Background()))
+
+	err := b.ReplaceAllHandlers(&GetUserQuery{}, getUserHandler)
+
+	assert.Equal(t, bus.ErrBusClosed, err)
+}
+
+func TestBus_ReplaceAllHandlers_DuplicateSubscriptionCheck(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDuplicateSubscriptionCheck(false))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	})
+
+	err := b.ReplaceAllHandlers(&GetUserQuery{}, getUserHandler, getUserHandler)
+
+	assert.True(t, errors.Is(err, bus.ErrAlreadySubscribed))
+	assert.Len(t, b.Subscriptions(), 1)
+	assert.NotEqual(t, "github.com/steinfletcher/bus_test.getUserHandler", b.Subscriptions()[0].HandlerName)
+}
+
+func TestBus_ReplaceAllHandlers_InterfaceType(t *testing.T) {
+	b := bus.New()
+	var audited []string
+
+	err := b.ReplaceAllHandlers((*DomainEvent)(nil), func(ctx context.Context, event DomainEvent) error {
+		audited = append(audited, event.AggregateID())
+		return nil
+	})
+	assert.NoError(t, err)
+	_ = b.Publish(context.Background(), &OrderCreated{ID: "1"})
+
+	assert.Equal(t, []string{"1"}, audited)
+}
+
+func BenchmarkBus_Publish(b *testing.B) {
+	msgBus := bus.New()
+	_ = msgBus.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	})
+	query := &GetUserQuery{ID: "1234"}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = msgBus.Publish(ctx, query)
+	}
+}
+
+func BenchmarkBus_Publish_HandlerNotFound(b *testing.B) {
+	msgBus := bus.New()
+	_ = msgBus.Subscribe(func(ctx context.Context, command SomeCommand) error {
+		return nil
+	})
+	query := &GetUserQuery{ID: "1234"}
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = msgBus.Publish(ctx, query)
+	}
+}
+
 func Test(t *testing.T) {
 	fn := func(ctx context.Context, arg *SomeCommand) {
 		fmt.Println(reflect.TypeOf(arg).String())
@@ -281,3 +1073,45 @@ func Test(t *testing.T) {
 	fmt.Println(handlerArg)
 	fn(context.Background(), &SomeCommand{})
 }
+
+func TestBus_Publish_ContextCancelled(t *testing.T) {
+	b := bus.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		t.Error("async handler called")
+	})
+	cancel()
+
+	err := b.Publish(ctx, &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestBus_Publish_ContextCancelledByHandler(t *testing.T) {
+	b := bus.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls []string
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		calls = append(calls, "first")
+		cancel()
+		return nil
+	})
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		calls = append(calls, "second")
+		return nil
+	})
+
+	err := b.Publish(ctx, &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, []string{"first"}, calls)
+}
+
+func TestBus_WithIsolatedContext_IgnoresCancellation(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithIsolatedContext())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = b.Subscribe(getUserHandler)
+
+	assert.NoError(t, b.Publish(ctx, &GetUserQuery{ID: "1234"}))
+}
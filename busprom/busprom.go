@@ -0,0 +1,97 @@
+// Package busprom exports the metrics of a bus to Prometheus
+//
+//	collector := busprom.NewCollector()
+//	prometheus.MustRegister(collector)
+//	msgBus := bus.NewWithOptions(bus.WithMetrics(collector))
+//
+// The following metrics are exported, labelled by message type and, for handler metrics, by handler name
+//
+//	bus_messages_published_total      counter    messages published
+//	bus_handler_duration_seconds      histogram  duration of handler invocations
+//	bus_handler_errors_total          counter    errors returned by handlers
+//	bus_messages_dropped_total        counter    messages discarded because the queue of an async handler is full
+//	bus_async_queue_depth             gauge      messages waiting in the queue of an async handler
+package busprom
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	messageTypeLabel = "message_type"
+	handlerLabel     = "handler"
+)
+
+// Collector is a bus.Collector that exports the metrics of a bus as Prometheus metrics. It implements
+// prometheus.Collector so it must be registered with a prometheus.Registerer
+type Collector struct {
+	published  *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+	errors     *prometheus.CounterVec
+	dropped    *prometheus.CounterVec
+	queueDepth *prometheus.GaugeVec
+}
+
+// NewCollector creates a Collector. Handler durations are recorded in the default Prometheus buckets
+func NewCollector() *Collector {
+	return &Collector{
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bus_messages_published_total",
+			Help: "Number of messages published to the bus.",
+		}, []string{messageTypeLabel}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bus_handler_duration_seconds",
+			Help:    "Duration of handler invocations.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{messageTypeLabel, handlerLabel}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bus_handler_errors_total",
+			Help: "Number of errors returned by handlers.",
+		}, []string{messageTypeLabel, handlerLabel}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "bus_messages_dropped_total",
+			Help: "Number of messages discarded because the queue of an async handler is full.",
+		}, []string{messageTypeLabel, handlerLabel}),
+		queueDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "bus_async_queue_depth",
+			Help: "Number of messages waiting in the queue of an async handler.",
+		}, []string{messageTypeLabel, handlerLabel}),
+	}
+}
+
+func (c *Collector) Published(msgType string) {
+	c.published.WithLabelValues(msgType).Inc()
+}
+
+func (c *Collector) Handled(msgType, handlerName string, elapsed time.Duration, err error) {
+	c.duration.WithLabelValues(msgType, handlerName).Observe(elapsed.Seconds())
+	if err != nil {
+		c.errors.WithLabelValues(msgType, handlerName).Inc()
+	}
+}
+
+func (c *Collector) Dropped(msgType, handlerName string) {
+	c.dropped.WithLabelValues(msgType, handlerName).Inc()
+}
+
+func (c *Collector) QueueDepth(msgType, handlerName string, depth int) {
+	c.queueDepth.WithLabelValues(msgType, handlerName).Set(float64(depth))
+}
+
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.published.Describe(ch)
+	c.duration.Describe(ch)
+	c.errors.Describe(ch)
+	c.dropped.Describe(ch)
+	c.queueDepth.Describe(ch)
+}
+
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.published.Collect(ch)
+	c.duration.Collect(ch)
+	c.errors.Collect(ch)
+	c.dropped.Collect(ch)
+	c.queueDepth.Collect(ch)
+}
@@ -0,0 +1,85 @@
+package busprom_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/busprom"
+	"github.com/stretchr/testify/assert"
+)
+
+type CreateUserCommand struct {
+	Name string
+}
+
+func createUser(ctx context.Context, cmd *CreateUserCommand) error {
+	if cmd.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+func TestCollector(t *testing.T) {
+	collector := busprom.NewCollector()
+	registry := prometheus.NewPedanticRegistry()
+	assert.NoError(t, registry.Register(collector))
+	b := bus.NewWithOptions(bus.WithMetrics(collector))
+	_ = b.Subscribe(createUser)
+
+	_ = b.Publish(context.Background(), &CreateUserCommand{Name: "Jan"})
+	_ = b.Publish(context.Background(), &CreateUserCommand{})
+
+	expected := `
+# HELP bus_handler_errors_total Number of errors returned by handlers.
+# TYPE bus_handler_errors_total counter
+bus_handler_errors_total{handler="github.com/steinfletcher/bus/busprom_test.createUser",message_type="*busprom_test.CreateUserCommand"} 1
+# HELP bus_messages_published_total Number of messages published to the bus.
+# TYPE bus_messages_published_total counter
+bus_messages_published_total{message_type="*busprom_test.CreateUserCommand"} 2
+`
+	err := testutil.GatherAndCompare(registry, strings.NewReader(expected), "bus_messages_published_total", "bus_handler_errors_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(collector, "bus_handler_duration_seconds"))
+}
+
+func TestCollector_AsyncQueue(t *testing.T) {
+	collector := busprom.NewCollector()
+	b := bus.NewWithOptions(bus.WithMetrics(collector), bus.WithDefaultQueueSize(1))
+	release := make(chan struct{})
+	_ = b.SubscribeAsync(func(ctx context.Context, cmd *CreateUserCommand) {
+		<-release
+	}, bus.WithBackpressure(bus.BackpressureDropNewest))
+
+	for i := 0; i < 5; i++ {
+		_ = b.Publish(context.Background(), &CreateUserCommand{Name: "Jan"})
+	}
+	close(release)
+	assert.NoError(t, b.Close(context.Background()))
+
+	// the handler holds at most one message and the queue one more, so at least three are dropped
+	assert.GreaterOrEqual(t, sum(t, collector, "bus_messages_dropped_total"), 3.0)
+	assert.Equal(t, 0.0, sum(t, collector, "bus_async_queue_depth"))
+}
+
+// sum returns the sum of the values of the named counter or gauge
+func sum(t *testing.T, collector prometheus.Collector, name string) float64 {
+	registry := prometheus.NewRegistry()
+	assert.NoError(t, registry.Register(collector))
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+	var total float64
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			total += metric.GetCounter().GetValue() + metric.GetGauge().GetValue()
+		}
+	}
+	return total
+}
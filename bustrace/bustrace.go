@@ -0,0 +1,84 @@
+// Package bustrace records the publishes and handler invocations of a bus as OpenTelemetry spans
+//
+//	msgBus := bus.NewWithOptions(bus.WithTracer(bustrace.New(otel.GetTracerProvider())))
+//
+// A span is started for each publish, with a child span for each handler invocation recording the handler name,
+// whether it is async, the time the message waited in the queue of an async handler and the error returned by the
+// handler. Async handlers continue the trace of the publisher even when they are given a context detached from the
+// publish context.
+package bustrace
+
+import (
+	"context"
+
+	"github.com/steinfletcher/bus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/steinfletcher/bus/bustrace"
+
+// Attribute keys recorded on the spans
+const (
+	MessageTypeKey = attribute.Key("bus.message_type")
+	HandlerKey     = attribute.Key("bus.handler")
+	AsyncKey       = attribute.Key("bus.async")
+	QueueWaitKey   = attribute.Key("bus.queue_wait_ms")
+)
+
+// Tracer is a bus.Tracer recording OpenTelemetry spans
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// New creates a Tracer recording spans with a tracer from tp
+func New(tp trace.TracerProvider) *Tracer {
+	return &Tracer{tracer: tp.Tracer(instrumentationName)}
+}
+
+func (t *Tracer) StartPublish(ctx context.Context, msgTypeName string, msg bus.Message) (context.Context, func(err error)) {
+	ctx, span := t.tracer.Start(ctx, "publish "+msgTypeName,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(MessageTypeKey.String(msgTypeName)),
+	)
+	return ctx, func(err error) {
+		end(span, err)
+	}
+}
+
+func (t *Tracer) StartHandler(ctx context.Context, info bus.HandlerInfo) (context.Context, func(err error)) {
+	kind := trace.SpanKindInternal
+	if info.Async {
+		kind = trace.SpanKindConsumer
+	}
+	ctx, span := t.tracer.Start(ctx, "handle "+info.MessageType,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(
+			MessageTypeKey.String(info.MessageType),
+			HandlerKey.String(info.HandlerName),
+			AsyncKey.Bool(info.Async),
+			QueueWaitKey.Float64(float64(info.QueueWait.Microseconds())/1000),
+		),
+	)
+	return ctx, func(err error) {
+		end(span, err)
+	}
+}
+
+// Propagate returns detached carrying the span of parent unless detached already carries a span
+func (t *Tracer) Propagate(parent, detached context.Context) context.Context {
+	if trace.SpanContextFromContext(detached).IsValid() {
+		return detached
+	}
+	return trace.ContextWithSpanContext(detached, trace.SpanContextFromContext(parent))
+}
+
+// end records err on span and ends it
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
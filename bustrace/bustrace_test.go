@@ -0,0 +1,82 @@
+package bustrace_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/bustrace"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type CreateUserCommand struct {
+	Name string
+}
+
+func newTracedBus(opts ...bus.Option) (bus.Bus, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return bus.NewWithOptions(append(opts, bus.WithTracer(bustrace.New(tp)))...), recorder
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	values := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		values[kv.Key] = kv.Value
+	}
+	return values
+}
+
+func TestTracer_Publish(t *testing.T) {
+	b, recorder := newTracedBus()
+	_ = b.Subscribe(func(ctx context.Context, cmd *CreateUserCommand) error {
+		return errors.New("name is required")
+	})
+
+	err := b.Publish(context.Background(), &CreateUserCommand{})
+
+	assert.Error(t, err)
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	handlerSpan, publishSpan := spans[0], spans[1]
+	assert.Equal(t, "publish *bustrace_test.CreateUserCommand", publishSpan.Name())
+	assert.Equal(t, "handle *bustrace_test.CreateUserCommand", handlerSpan.Name())
+	assert.Equal(t, publishSpan.SpanContext().SpanID(), handlerSpan.Parent().SpanID())
+	assert.Equal(t, codes.Error, handlerSpan.Status().Code)
+	assert.Equal(t, codes.Error, publishSpan.Status().Code)
+	assert.False(t, attributes(handlerSpan)[bustrace.AsyncKey].AsBool())
+	assert.Contains(t, attributes(handlerSpan)[bustrace.HandlerKey].AsString(), "TestTracer_Publish")
+}
+
+func TestTracer_AsyncHandlerContinuesTrace(t *testing.T) {
+	b, recorder := newTracedBus(bus.WithAsyncLockContext(func(parent context.Context) context.Context {
+		return context.Background()
+	}))
+	handled := make(chan struct{})
+	_ = b.SubscribeAsync(func(ctx context.Context, cmd *CreateUserCommand) {
+		close(handled)
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &CreateUserCommand{Name: "Jan"}))
+	<-handled
+	assert.NoError(t, b.Close(context.Background()))
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	var publishSpan, handlerSpan sdktrace.ReadOnlySpan
+	for _, span := range spans {
+		if attributes(span)[bustrace.AsyncKey].AsBool() {
+			handlerSpan = span
+		} else {
+			publishSpan = span
+		}
+	}
+	assert.Equal(t, publishSpan.SpanContext().TraceID(), handlerSpan.SpanContext().TraceID())
+	assert.Equal(t, publishSpan.SpanContext().SpanID(), handlerSpan.Parent().SpanID())
+	assert.Contains(t, attributes(handlerSpan), bustrace.QueueWaitKey)
+}
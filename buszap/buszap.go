@@ -0,0 +1,15 @@
+// Package buszap writes the messages logged by a bus to a zap logger
+//
+//	msgBus := bus.NewWithOptions(bus.WithLogger(buszap.New(logger)), bus.WithVerbosity(bus.VerbositySubscriptions))
+package buszap
+
+import (
+	"github.com/steinfletcher/bus"
+	"go.uber.org/zap"
+)
+
+// New returns a bus.Logger writing to l at the corresponding level. Messages are only formatted if their level is
+// enabled
+func New(l *zap.Logger) bus.Logger {
+	return l.Sugar()
+}
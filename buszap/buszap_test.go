@@ -0,0 +1,31 @@
+package buszap_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/buszap"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type CreateUserCommand struct {
+	Name string
+}
+
+func TestNew(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	b := bus.NewWithOptions(bus.WithLogger(buszap.New(zap.New(core))), bus.WithVerbosity(bus.VerbositySubscriptions))
+
+	_ = b.Subscribe(func(ctx context.Context, cmd *CreateUserCommand) error { return nil })
+	_ = b.Publish(context.Background(), struct{}{})
+
+	entries := logs.AllUntimed()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, zapcore.InfoLevel, entries[0].Level)
+	assert.Contains(t, entries[0].Message, "subscribed sync handler")
+	assert.Contains(t, entries[0].Message, "to *buszap_test.CreateUserCommand")
+}
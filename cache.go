@@ -0,0 +1,58 @@
+package bus
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// ttlCache records keys for a fixed duration. Expired keys are evicted when new keys are added
+type ttlCache struct {
+	sync.Mutex
+	ttl   time.Duration
+	items map[string]time.Time
+	// expiry holds the cacheEntry of every item in the order they were added, which is the order they expire in as
+	// all items are recorded for the same duration
+	expiry *list.List
+}
+
+type cacheEntry struct {
+	key       string
+	expiresAt time.Time
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{
+		ttl:    ttl,
+		items:  make(map[string]time.Time),
+		expiry: list.New(),
+	}
+}
+
+// Add records the key and reports whether it was added. False is returned if the key is already present and has not
+// expired
+func (c *ttlCache) Add(key string) bool {
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	c.evict(now)
+	if _, ok := c.items[key]; ok {
+		return false
+	}
+	expiresAt := now.Add(c.ttl)
+	c.items[key] = expiresAt
+	c.expiry.PushBack(cacheEntry{key: key, expiresAt: expiresAt})
+	return true
+}
+
+// evict removes the items that have expired at now
+func (c *ttlCache) evict(now time.Time) {
+	for front := c.expiry.Front(); front != nil; front = c.expiry.Front() {
+		entry := front.Value.(cacheEntry)
+		if now.Before(entry.expiresAt) {
+			return
+		}
+		c.expiry.Remove(front)
+		delete(c.items, entry.key)
+	}
+}
@@ -0,0 +1,85 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrBusClosed is returned when publishing to or subscribing to a bus that has been closed
+var ErrBusClosed = errors.New("bus closed")
+
+// closePollInterval is the interval at which Close checks whether publishes and async handlers have completed
+const closePollInterval = time.Millisecond
+
+func (e *eventBus) Close(ctx context.Context) error {
+	if !e.closed.CompareAndSwap(false, true) {
+		return ErrBusClosed
+	}
+	close(e.done)
+
+	var queues []*asyncQueue
+	var coalescers []*coalescer
+	for _, key := range e.handlers.Keys() {
+		handlers, _ := e.handlers.Get(key)
+		for _, handler := range handlers {
+			if handler.isAsync {
+				queues = append(queues, handler.queue)
+			}
+			if handler.coalescer != nil {
+				coalescers = append(coalescers, handler.coalescer)
+			}
+		}
+	}
+	defer func() {
+		for _, queue := range queues {
+			queue.Close()
+		}
+	}()
+
+	if err := waitUntil(ctx, func() bool { return atomic.LoadInt64(&e.inflight) == 0 }); err != nil {
+		return err
+	}
+	for _, c := range coalescers {
+		if err := c.Drain(ctx); err != nil {
+			return err
+		}
+	}
+	for _, queue := range queues {
+		if err := waitUntil(ctx, queue.Empty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enter records the start of a publish and reports whether the bus accepts it. Each call that returns true must be
+// followed by a call to exit
+func (e *eventBus) enter() bool {
+	atomic.AddInt64(&e.inflight, 1)
+	if e.closed.Load() {
+		atomic.AddInt64(&e.inflight, -1)
+		return false
+	}
+	return true
+}
+
+// exit records the end of a publish
+func (e *eventBus) exit() {
+	atomic.AddInt64(&e.inflight, -1)
+}
+
+// waitUntil polls done until it returns true or ctx is done
+func waitUntil(ctx context.Context, done func() bool) error {
+	ticker := time.NewTicker(closePollInterval)
+	defer ticker.Stop()
+	for !done() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package bus_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_Close(t *testing.T) {
+	b := bus.New()
+	var handled atomic.Int32
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		time.Sleep(5 * time.Millisecond)
+		handled.Add(1)
+	})
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+	}
+
+	assert.NoError(t, b.Close(context.Background()))
+
+	assert.Equal(t, int32(10), handled.Load())
+	assert.Equal(t, bus.ErrBusClosed, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+	assert.Equal(t, bus.ErrBusClosed, b.Subscribe(getUserHandler))
+	assert.Equal(t, bus.ErrBusClosed, b.Close(context.Background()))
+}
+
+func TestBus_Close_WaitsForSyncHandlers(t *testing.T) {
+	b := bus.New()
+	started := make(chan struct{})
+	var handled atomic.Bool
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		close(started)
+		time.Sleep(20 * time.Millisecond)
+		handled.Store(true)
+		return nil
+	})
+	go func() {
+		_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	}()
+	<-started
+
+	assert.NoError(t, b.Close(context.Background()))
+
+	assert.True(t, handled.Load())
+}
+
+func TestBus_Close_ContextDone(t *testing.T) {
+	b := bus.New()
+	release := make(chan struct{})
+	defer close(release)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		<-release
+	})
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := b.Close(ctx)
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
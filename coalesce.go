@@ -0,0 +1,95 @@
+package bus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+func (e *eventBus) SubscribeCoalesced(fn interface{}, debounce time.Duration) error {
+	return e.subscribeHandler(fn, handler{coalescer: &coalescer{debounce: debounce, bus: e}})
+}
+
+// coalescer delays calling the handler until no messages have been received for the debounce duration. The handler
+// is then called with the last message received, as an async handler is
+type coalescer struct {
+	mu       sync.Mutex
+	handler  handler
+	debounce time.Duration
+	bus      *eventBus
+	timer    *time.Timer
+	last     []reflect.Value
+	// running is the number of flushes calling the handler
+	running int
+	stopped bool
+}
+
+// push replaces the pending message with params and restarts the debounce timer. The handler is called with a
+// context that is not cancelled with the publish context, as it is called after the publish returns
+func (c *coalescer) push(params []reflect.Value) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return
+	}
+	detached := make([]reflect.Value, len(params))
+	copy(detached, params)
+	detached[0] = reflect.ValueOf(context.WithoutCancel(params[0].Interface().(context.Context)))
+	c.last = detached
+	if c.timer == nil {
+		c.timer = time.AfterFunc(c.debounce, c.flush)
+	} else {
+		c.timer.Reset(c.debounce)
+	}
+}
+
+func (c *coalescer) flush() {
+	c.mu.Lock()
+	params := c.last
+	c.last = nil
+	if params != nil {
+		c.running++
+	}
+	c.mu.Unlock()
+	if params == nil {
+		return
+	}
+	defer func() {
+		c.mu.Lock()
+		c.running--
+		c.mu.Unlock()
+	}()
+	_ = c.bus.handleAsync(c.handler, params)
+}
+
+// Stop stops the timer and discards the pending message
+func (c *coalescer) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopped = true
+	c.last = nil
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+// Drain stops the timer, calls the handler with the pending message without waiting for the debounce duration and
+// waits until the handler has returned or ctx is done
+func (c *coalescer) Drain(ctx context.Context) error {
+	c.mu.Lock()
+	c.stopped = true
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+	c.mu.Unlock()
+	go c.flush()
+	return waitUntil(ctx, c.idle)
+}
+
+// idle reports whether there is no pending message and no flush is calling the handler
+func (c *coalescer) idle() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last == nil && c.running == 0
+}
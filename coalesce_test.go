@@ -0,0 +1,104 @@
+package bus_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_SubscribeCoalesced(t *testing.T) {
+	b := bus.New()
+	var mu sync.Mutex
+	var received []string
+	_ = b.SubscribeCoalesced(func(ctx context.Context, query *GetUserQuery) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, query.ID)
+		return nil
+	}, 50*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		err := b.Publish(context.Background(), &GetUserQuery{ID: fmt.Sprint(i)})
+		assert.NoError(t, err)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"9"}, received)
+}
+
+func TestBus_SubscribeCoalesced_QuietPeriods(t *testing.T) {
+	b := bus.New()
+	received := make(chan string, 2)
+	_ = b.SubscribeCoalesced(func(ctx context.Context, query *GetUserQuery) {
+		received <- query.ID
+	}, 20*time.Millisecond)
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1"})
+	assert.Equal(t, "1", <-received)
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "2"})
+	assert.Equal(t, "2", <-received)
+}
+
+func TestBus_SubscribeCoalesced_AsyncErrorHandler(t *testing.T) {
+	errs := make(chan error, 1)
+	b := bus.NewWithOptions(bus.WithPanicRecovery(true), bus.WithAsyncErrorHandler(func(ctx context.Context, msg bus.Message, err error) {
+		errs <- err
+	}))
+	_ = b.SubscribeCoalesced(func(ctx context.Context, query *GetUserQuery) error {
+		panic("boom")
+	}, 10*time.Millisecond)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1"}))
+
+	var panicErr *bus.PanicError
+	assert.ErrorAs(t, <-errs, &panicErr)
+}
+
+func TestBus_SubscribeCoalesced_DetachedContext(t *testing.T) {
+	b := bus.New()
+	received := make(chan error, 1)
+	_ = b.SubscribeCoalesced(func(ctx context.Context, query *GetUserQuery) {
+		received <- ctx.Err()
+	}, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	assert.NoError(t, b.Publish(ctx, &GetUserQuery{ID: "1"}))
+	cancel()
+
+	assert.NoError(t, <-received)
+}
+
+func TestBus_SubscribeCoalesced_Close(t *testing.T) {
+	b := bus.New()
+	var received []string
+	_ = b.SubscribeCoalesced(func(ctx context.Context, query *GetUserQuery) {
+		received = append(received, query.ID)
+	}, time.Hour)
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1"}))
+
+	assert.NoError(t, b.Close(context.Background()))
+
+	assert.Equal(t, []string{"1"}, received)
+}
+
+func TestBus_SubscribeCoalesced_Unsubscribe(t *testing.T) {
+	b := bus.New()
+	received := make(chan string, 1)
+	handler := func(ctx context.Context, query *GetUserQuery) {
+		received <- query.ID
+	}
+	_ = b.SubscribeCoalesced(handler, 10*time.Millisecond)
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1"}))
+
+	assert.NoError(t, b.Unsubscribe(handler))
+	time.Sleep(30 * time.Millisecond)
+
+	assert.Empty(t, received)
+}
@@ -0,0 +1,62 @@
+package bus
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// AutoSubscribeByConvention subscribes each exported method of svc whose name starts with prefix, e.g. "On", and
+// returns the number of methods subscribed. Methods that do not have a handler signature are skipped. Methods are
+// subscribed asynchronously if async is true. For example
+//
+//	type UserService struct{}
+//
+//	func (s *UserService) OnGetUser(ctx context.Context, query *GetUserQuery) error {
+//	   return nil
+//	}
+//
+//	n, err := bus.AutoSubscribeByConvention(msgBus, &UserService{}, "On", false)
+//
+// Subscription stops at the first error, which is returned with the number of methods subscribed before it. Handlers
+// are named after their method, e.g. github.com/org/users.(*UserService).OnGetUser.
+func AutoSubscribeByConvention(b Bus, svc interface{}, prefix string, async bool) (int, error) {
+	value := reflect.ValueOf(svc)
+	typ := value.Type()
+	var count int
+	for i := 0; i < typ.NumMethod(); i++ {
+		method := typ.Method(i)
+		if !strings.HasPrefix(method.Name, prefix) {
+			continue
+		}
+		fn := value.Method(i).Interface()
+		if validateHandler(fn) != nil {
+			continue
+		}
+		name := WithHandlerName(MethodName(typ, method.Name))
+		var err error
+		if async {
+			err = b.SubscribeAsync(fn, name)
+		} else {
+			err = b.Subscribe(fn, name)
+		}
+		if err != nil {
+			return count, fmt.Errorf("failed to subscribe %s.%s: %w", typ, method.Name, err)
+		}
+		count++
+	}
+	return count, nil
+}
+
+// MethodName returns the fully qualified name of the method of typ in the form used by the runtime for method
+// expressions, e.g. github.com/org/users.(*UserService).OnGetUser. Use it with WithHandlerName to name handlers that
+// are method values, which are otherwise named after a wrapper shared by all method values
+func MethodName(typ reflect.Type, method string) string {
+	if typ.Kind() == reflect.Pointer && typ.Elem().Name() != "" {
+		return fmt.Sprintf("%s.(*%s).%s", typ.Elem().PkgPath(), typ.Elem().Name(), method)
+	}
+	if typ.Name() != "" {
+		return fmt.Sprintf("%s.%s.%s", typ.PkgPath(), typ.Name(), method)
+	}
+	return fmt.Sprintf("%s.%s", typ, method)
+}
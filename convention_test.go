@@ -0,0 +1,74 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type CreateUserCommand struct {
+	Name string
+}
+
+type userService struct {
+	queries  []*GetUserQuery
+	commands []*CreateUserCommand
+}
+
+func (s *userService) OnGetUser(ctx context.Context, query *GetUserQuery) error {
+	s.queries = append(s.queries, query)
+	return nil
+}
+
+func (s *userService) OnCreateUser(ctx context.Context, cmd *CreateUserCommand) error {
+	s.commands = append(s.commands, cmd)
+	return nil
+}
+
+func (s *userService) OnTick() {}
+
+func (s *userService) GetUser(ctx context.Context, query *GetUserQuery) error {
+	return nil
+}
+
+func TestAutoSubscribeByConvention(t *testing.T) {
+	b := bus.New()
+	svc := &userService{}
+
+	n, err := bus.AutoSubscribeByConvention(b, svc, "On", false)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+	assert.NoError(t, b.Publish(context.Background(), &CreateUserCommand{Name: "Jan"}))
+	assert.Equal(t, []*GetUserQuery{{ID: "1234"}}, svc.queries)
+	assert.Equal(t, []*CreateUserCommand{{Name: "Jan"}}, svc.commands)
+}
+
+func TestAutoSubscribeByConvention_HandlerNames(t *testing.T) {
+	b := bus.New()
+
+	_, err := bus.AutoSubscribeByConvention(b, &userService{}, "On", true)
+
+	assert.NoError(t, err)
+	var names []string
+	for _, subscription := range b.Subscriptions() {
+		names = append(names, subscription.HandlerName)
+	}
+	assert.ElementsMatch(t, []string{
+		"github.com/steinfletcher/bus_test.(*userService).OnCreateUser",
+		"github.com/steinfletcher/bus_test.(*userService).OnGetUser",
+	}, names)
+}
+
+func TestAutoSubscribeByConvention_Error(t *testing.T) {
+	b := bus.New()
+	assert.NoError(t, b.Subscribe(getUserHandler, bus.WithSingleHandler()))
+
+	n, err := bus.AutoSubscribeByConvention(b, &userService{}, "On", false)
+
+	assert.ErrorIs(t, err, bus.ErrMultipleHandlers)
+	assert.Equal(t, 1, n)
+}
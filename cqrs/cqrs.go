@@ -0,0 +1,100 @@
+// Package cqrs provides command, query and event facades over a bus, each enforcing the semantics of its kind of
+// message. Commands and queries have exactly one handler, whose error is returned to the caller, and queries return
+// the result of their handler. Events have any number of handlers, which are called asynchronously.
+package cqrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/steinfletcher/bus"
+)
+
+// ErrHandlerAlreadyRegistered is returned when registering a command or query handler for a message type that already
+// has a handler
+var ErrHandlerAlreadyRegistered = errors.New("handler already registered")
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// registered returns err wrapped with ErrHandlerAlreadyRegistered if the handler was rejected because its message
+// type already has a handler
+func registered(err error) error {
+	if errors.Is(err, bus.ErrMultipleHandlers) {
+		return fmt.Errorf("%w: %w", ErrHandlerAlreadyRegistered, err)
+	}
+	return err
+}
+
+// CommandBus dispatches commands to their single handler
+type CommandBus struct {
+	bus bus.Bus
+}
+
+// NewCommandBus creates a CommandBus that subscribes and publishes commands to b
+func NewCommandBus(b bus.Bus) *CommandBus {
+	return &CommandBus{bus: b}
+}
+
+// Handle registers fn as the handler of its command type. fn must have the signature
+// func(ctx context.Context, cmd *Command) error. An error wrapping ErrHandlerAlreadyRegistered is returned if the
+// command type already has a handler.
+func (c *CommandBus) Handle(fn interface{}) error {
+	fnType := reflect.TypeOf(fn)
+	if fnType.Kind() != reflect.Func || fnType.NumIn() < 2 || fnType.NumOut() != 1 || fnType.Out(0) != errorType {
+		return fmt.Errorf("command handler '%s' must be a function returning an error", fnType)
+	}
+	return registered(c.bus.Subscribe(fn, bus.WithSingleHandler()))
+}
+
+// Dispatch calls the handler of cmd and returns its error. bus.ErrHandlerNotFound is returned if the command type has
+// no handler.
+func (c *CommandBus) Dispatch(ctx context.Context, cmd bus.Message) error {
+	return c.bus.Publish(ctx, cmd)
+}
+
+// QueryBus sends queries to their single handler and returns its result
+type QueryBus struct {
+	bus bus.Bus
+}
+
+// NewQueryBus creates a QueryBus that subscribes and publishes queries to b
+func NewQueryBus(b bus.Bus) *QueryBus {
+	return &QueryBus{bus: b}
+}
+
+// Handle registers fn as the handler of its query type. fn must have the signature
+// func(ctx context.Context, query *Query) (Result, error). An error wrapping ErrHandlerAlreadyRegistered is returned
+// if the query type already has a handler.
+func (q *QueryBus) Handle(fn interface{}) error {
+	return registered(q.bus.SubscribeRequest(fn))
+}
+
+// Query calls the handler of query and returns its result and error. bus.ErrHandlerNotFound is returned if the query
+// type has no handler.
+func (q *QueryBus) Query(ctx context.Context, query bus.Message) (interface{}, error) {
+	return q.bus.Request(ctx, query)
+}
+
+// EventBus publishes events to any number of handlers without waiting for them
+type EventBus struct {
+	bus bus.Bus
+}
+
+// NewEventBus creates an EventBus that subscribes and publishes events to b
+func NewEventBus(b bus.Bus) *EventBus {
+	return &EventBus{bus: b}
+}
+
+// Subscribe registers fn as an asynchronous handler of its event type. Handler errors are not returned to publishers,
+// see bus.WithAsyncErrorHandler
+func (e *EventBus) Subscribe(fn interface{}) error {
+	return e.bus.SubscribeAsync(fn)
+}
+
+// Publish queues event for its handlers and returns without waiting for them. Publishing an event without handlers is
+// not an error.
+func (e *EventBus) Publish(ctx context.Context, event bus.Message) error {
+	return e.bus.PublishOptional(ctx, event)
+}
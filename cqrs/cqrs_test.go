@@ -0,0 +1,120 @@
+package cqrs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/cqrs"
+	"github.com/stretchr/testify/assert"
+)
+
+type CreateUserCommand struct {
+	Name string
+}
+
+type GetUserQuery struct {
+	ID string
+}
+
+type UserCreated struct {
+	Name string
+}
+
+func TestCommandBus_Dispatch(t *testing.T) {
+	commands := cqrs.NewCommandBus(bus.New())
+	err := commands.Handle(func(ctx context.Context, cmd *CreateUserCommand) error {
+		if cmd.Name == "" {
+			return errors.New("name is required")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, commands.Dispatch(context.Background(), &CreateUserCommand{Name: "Jan"}))
+	assert.EqualError(t, commands.Dispatch(context.Background(), &CreateUserCommand{}), "name is required")
+}
+
+func TestCommandBus_HandleRejectsSecondHandler(t *testing.T) {
+	commands := cqrs.NewCommandBus(bus.New())
+	handler := func(ctx context.Context, cmd *CreateUserCommand) error { return nil }
+	assert.NoError(t, commands.Handle(handler))
+
+	err := commands.Handle(handler)
+
+	assert.True(t, errors.Is(err, cqrs.ErrHandlerAlreadyRegistered))
+}
+
+func TestCommandBus_HandlerIsExclusive(t *testing.T) {
+	b := bus.New()
+	commands := cqrs.NewCommandBus(b)
+	assert.NoError(t, commands.Handle(func(ctx context.Context, cmd *CreateUserCommand) error { return nil }))
+
+	err := b.Subscribe(func(ctx context.Context, cmd *CreateUserCommand) error { return nil })
+
+	assert.True(t, errors.Is(err, bus.ErrMultipleHandlers))
+}
+
+func TestCommandBus_DispatchWithoutHandler(t *testing.T) {
+	commands := cqrs.NewCommandBus(bus.New())
+
+	err := commands.Dispatch(context.Background(), &CreateUserCommand{})
+
+	assert.True(t, errors.Is(err, bus.ErrHandlerNotFound))
+}
+
+func TestQueryBus_Query(t *testing.T) {
+	queries := cqrs.NewQueryBus(bus.New())
+	err := queries.Handle(func(ctx context.Context, query *GetUserQuery) (string, error) {
+		if query.ID != "1" {
+			return "", errors.New("user not found")
+		}
+		return "Jan", nil
+	})
+	assert.NoError(t, err)
+
+	result, err := queries.Query(context.Background(), &GetUserQuery{ID: "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Jan", result)
+
+	_, err = queries.Query(context.Background(), &GetUserQuery{ID: "2"})
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestQueryBus_HandleRequiresResult(t *testing.T) {
+	queries := cqrs.NewQueryBus(bus.New())
+
+	err := queries.Handle(func(ctx context.Context, query *GetUserQuery) error { return nil })
+
+	assert.Error(t, err)
+}
+
+func TestEventBus_Publish(t *testing.T) {
+	events := cqrs.NewEventBus(bus.New())
+	received := make(chan string, 2)
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, events.Subscribe(func(ctx context.Context, event *UserCreated) error {
+			received <- event.Name
+			return nil
+		}))
+	}
+
+	assert.NoError(t, events.Publish(context.Background(), &UserCreated{Name: "Jan"}))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case name := <-received:
+			assert.Equal(t, "Jan", name)
+		case <-time.After(time.Second):
+			t.Fatal("event was not handled")
+		}
+	}
+}
+
+func TestEventBus_PublishWithoutHandlers(t *testing.T) {
+	events := cqrs.NewEventBus(bus.New())
+
+	assert.NoError(t, events.Publish(context.Background(), &UserCreated{}))
+}
@@ -0,0 +1,142 @@
+package bus
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// DeadLetterEnvelope is published to the dead letter bus for each message that could not be handled
+type DeadLetterEnvelope struct {
+	// OriginalMessage is the message that was published
+	OriginalMessage Message
+	// Reason is the error that caused the message to be dead lettered
+	Reason error
+	// AttemptCount is the number of times a handler was invoked with the message
+	AttemptCount int
+}
+
+// WithDeadLetterBus publishes messages that could not be handled to dlq as a DeadLetterEnvelope. Messages are dead
+// lettered when they are published without any subscribers or when an async handler returns an error. Subscribe to
+// the envelope on the dead letter bus like so
+//
+//	dlq.Subscribe(func(ctx context.Context, envelope bus.DeadLetterEnvelope) error {
+//	   return nil
+//	})
+//
+// Errors from publishing to the dead letter bus are logged, see WithLogger.
+func WithDeadLetterBus(dlq Bus) Option {
+	return func(e *eventBus) {
+		e.deadLetterBus = dlq
+	}
+}
+
+// DeadLetterHandler is called with a message that could not be handled and the reason it failed
+type DeadLetterHandler func(ctx context.Context, msg Message, err error)
+
+// WithTypedDeadLetterHandlers routes messages that could not be handled to the handler registered for the message
+// type. Message types are given as sample values, e.g. &GetUserQuery{}. The handler registered with a nil key is
+// called for message types without a handler. Messages are dead lettered under the same conditions as
+// WithDeadLetterBus.
+func WithTypedDeadLetterHandlers(handlers map[interface{}]func(ctx context.Context, msg Message, err error)) Option {
+	return func(e *eventBus) {
+		e.deadLetterHandlers = make(map[string]DeadLetterHandler)
+		for msgType, handler := range handlers {
+			if msgType == nil {
+				e.deadLetterFallback = handler
+				continue
+			}
+			e.deadLetterHandlers[reflect.TypeOf(msgType).String()] = handler
+		}
+	}
+}
+
+func (e *eventBus) SubscribeDeadLetter(fn func(ctx context.Context, envelope DeadLetterEnvelope)) error {
+	if e.closed.Load() {
+		return ErrBusClosed
+	}
+	e.deadLetters.Lock()
+	defer e.deadLetters.Unlock()
+	e.deadLetters.subscribers = append(e.deadLetters.subscribers, fn)
+	return nil
+}
+
+// WithDeadLetterQueue keeps up to size dead lettered messages in memory, discarding the oldest once full, so that they
+// can be read with DrainDeadLetters. Messages are dead lettered under the same conditions as WithDeadLetterBus.
+func WithDeadLetterQueue(size int) Option {
+	return func(e *eventBus) {
+		e.deadLetters.size = size
+	}
+}
+
+// DrainDeadLetters removes and returns the messages kept by the dead letter queue of b, oldest first. It returns nil
+// if b was not created with WithDeadLetterQueue.
+func DrainDeadLetters(b Bus) []DeadLetterEnvelope {
+	e, ok := b.(*eventBus)
+	if !ok {
+		return nil
+	}
+	e.deadLetters.Lock()
+	defer e.deadLetters.Unlock()
+	drained := e.deadLetters.queue
+	e.deadLetters.queue = nil
+	return drained
+}
+
+// deadLetters holds the dead letter subscribers and queue of a bus
+type deadLetters struct {
+	sync.Mutex
+	subscribers []func(ctx context.Context, envelope DeadLetterEnvelope)
+	// size is the capacity of the queue. Dead letters are not queued if it is zero
+	size  int
+	queue []DeadLetterEnvelope
+}
+
+// Notify queues the envelope and passes it to the subscribers
+func (d *deadLetters) Notify(ctx context.Context, envelope DeadLetterEnvelope) {
+	d.Lock()
+	if d.size > 0 {
+		if len(d.queue) == d.size {
+			// clear the discarded slot so that its message can be garbage collected before the array is reallocated
+			d.queue[0] = DeadLetterEnvelope{}
+			d.queue = d.queue[1:]
+		}
+		d.queue = append(d.queue, envelope)
+	}
+	subscribers := d.subscribers
+	d.Unlock()
+	for _, fn := range subscribers {
+		fn(ctx, envelope)
+	}
+}
+
+// deadLetter passes the message to the dead letter handlers and subscribers, queues it and publishes it to the dead
+// letter bus if configured
+func (e *eventBus) deadLetter(ctx context.Context, msg Message, reason error, attempts int) {
+	if handler, ok := e.deadLetterHandlers[reflect.TypeOf(msg).String()]; ok {
+		handler(ctx, msg, reason)
+	} else if e.deadLetterFallback != nil {
+		e.deadLetterFallback(ctx, msg, reason)
+	}
+	envelope := DeadLetterEnvelope{
+		OriginalMessage: msg,
+		Reason:          reason,
+		AttemptCount:    attempts,
+	}
+	e.deadLetters.Notify(ctx, envelope)
+	if e.deadLetterBus == nil {
+		return
+	}
+	if err := e.deadLetterBus.Publish(ctx, envelope); err != nil {
+		e.logger.Warnf("failed to publish %T to the dead letter bus: %v", msg, err)
+	}
+}
+
+// resultError returns the error returned by a handler, or nil if the handler has no return value
+func resultError(result []reflect.Value) error {
+	if len(result) == 0 {
+		return nil
+	}
+	err, _ := result[0].Interface().(error)
+	return err
+}
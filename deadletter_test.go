@@ -0,0 +1,144 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_WithDeadLetterBus_HandlerNotFound(t *testing.T) {
+	dlq := bus.New()
+	var envelope bus.DeadLetterEnvelope
+	_ = dlq.Subscribe(func(ctx context.Context, e bus.DeadLetterEnvelope) error {
+		envelope = e
+		return nil
+	})
+	b := bus.NewWithOptions(bus.WithDeadLetterBus(dlq))
+
+	query := &GetUserQuery{ID: "1234"}
+	err := b.Publish(context.Background(), query)
+
+	assert.Equal(t, bus.ErrHandlerNotFound, err)
+	assert.Equal(t, bus.DeadLetterEnvelope{
+		OriginalMessage: query,
+		Reason:          bus.ErrHandlerNotFound,
+		AttemptCount:    0,
+	}, envelope)
+}
+
+func TestBus_WithDeadLetterBus_AsyncHandlerError(t *testing.T) {
+	dlq := bus.New()
+	envelopes := make(chan bus.DeadLetterEnvelope, 1)
+	_ = dlq.Subscribe(func(ctx context.Context, e bus.DeadLetterEnvelope) error {
+		envelopes <- e
+		return nil
+	})
+	b := bus.NewWithOptions(bus.WithDeadLetterBus(dlq))
+	handlerErr := errors.New("failed to get user")
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) error {
+		return handlerErr
+	})
+
+	query := &GetUserQuery{ID: "1234"}
+	err := b.Publish(context.Background(), query)
+
+	assert.NoError(t, err)
+	select {
+	case envelope := <-envelopes:
+		assert.Equal(t, bus.DeadLetterEnvelope{
+			OriginalMessage: query,
+			Reason:          handlerErr,
+			AttemptCount:    1,
+		}, envelope)
+	case <-time.After(time.Second):
+		t.Fatal("message was not dead lettered")
+	}
+}
+
+func TestBus_WithTypedDeadLetterHandlers(t *testing.T) {
+	var mu sync.Mutex
+	routed := make(map[string][]string)
+	record := func(name string) func(ctx context.Context, msg bus.Message, err error) {
+		return func(ctx context.Context, msg bus.Message, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			routed[name] = append(routed[name], err.Error())
+		}
+	}
+	b := bus.NewWithOptions(bus.WithTypedDeadLetterHandlers(map[interface{}]func(ctx context.Context, msg bus.Message, err error){
+		&GetUserQuery{}: record("query"),
+		SomeCommand{}:   record("command"),
+		nil:             record("fallback"),
+	}))
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	_ = b.SubscribeAsync(func(ctx context.Context, command SomeCommand) error {
+		defer wg.Done()
+		return errors.New("command failed")
+	})
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	_ = b.Publish(context.Background(), SomeCommand{ID: "1234"})
+	_ = b.Publish(context.Background(), UserResult{Name: "Jan"})
+	wg.Wait()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(routed["command"]) == 1
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string][]string{
+		"query":    {"handler not found"},
+		"command":  {"command failed"},
+		"fallback": {"handler not found"},
+	}, routed)
+}
+
+func TestBus_SubscribeDeadLetter(t *testing.T) {
+	b := bus.New()
+	envelopes := make(chan bus.DeadLetterEnvelope, 2)
+	assert.NoError(t, b.SubscribeDeadLetter(func(ctx context.Context, envelope bus.DeadLetterEnvelope) {
+		envelopes <- envelope
+	}))
+	_ = b.SubscribeAsync(panickingHandler)
+
+	assert.Equal(t, bus.ErrHandlerNotFound, b.Publish(context.Background(), &SomeCommand{}))
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	notFound := <-envelopes
+	assert.Equal(t, bus.ErrHandlerNotFound, notFound.Reason)
+	panicked := <-envelopes
+	assert.Equal(t, &GetUserQuery{ID: "1234"}, panicked.OriginalMessage)
+	assert.Equal(t, 1, panicked.AttemptCount)
+	var panicErr *bus.PanicError
+	assert.ErrorAs(t, panicked.Reason, &panicErr)
+}
+
+func TestBus_WithDeadLetterQueue(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDeadLetterQueue(2))
+	for _, id := range []string{"1", "2", "3"} {
+		_ = b.Publish(context.Background(), &GetUserQuery{ID: id})
+	}
+
+	drained := bus.DrainDeadLetters(b)
+
+	assert.Equal(t, []bus.DeadLetterEnvelope{
+		{OriginalMessage: &GetUserQuery{ID: "2"}, Reason: bus.ErrHandlerNotFound},
+		{OriginalMessage: &GetUserQuery{ID: "3"}, Reason: bus.ErrHandlerNotFound},
+	}, drained)
+	assert.Empty(t, bus.DrainDeadLetters(b))
+}
+
+func TestBus_DrainDeadLetters_NoQueue(t *testing.T) {
+	b := bus.New()
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1"})
+
+	assert.Nil(t, bus.DrainDeadLetters(b))
+}
@@ -0,0 +1,17 @@
+package bus
+
+// Decorator wraps a handler function and returns a new handler function. The returned function must have the same
+// signature as fn so that it can be subscribed in place of fn.
+type Decorator func(fn interface{}) interface{}
+
+// Decorate applies the decorators to the handler fn in order, so the first decorator wraps fn, the second decorator
+// wraps the result of the first and so on. The last decorator is therefore the outermost and runs first when the
+// handler is invoked. The result can be passed to Subscribe
+//
+// msgBus.Subscribe(bus.Decorate(handler, logging, timing))
+func Decorate(fn interface{}, decorators ...Decorator) interface{} {
+	for _, decorator := range decorators {
+		fn = decorator(fn)
+	}
+	return fn
+}
@@ -0,0 +1,60 @@
+package bus_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecorate(t *testing.T) {
+	var calls []string
+	var elapsed time.Duration
+
+	logging := func(fn interface{}) interface{} {
+		handler := reflect.ValueOf(fn)
+		return reflect.MakeFunc(handler.Type(), func(args []reflect.Value) []reflect.Value {
+			calls = append(calls, "logging")
+			return handler.Call(args)
+		}).Interface()
+	}
+	timing := func(fn interface{}) interface{} {
+		handler := reflect.ValueOf(fn)
+		return reflect.MakeFunc(handler.Type(), func(args []reflect.Value) []reflect.Value {
+			calls = append(calls, "timing")
+			start := time.Now()
+			defer func() {
+				elapsed = time.Since(start)
+			}()
+			return handler.Call(args)
+		}).Interface()
+	}
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		calls = append(calls, "handler")
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}
+
+	b := bus.New()
+	err := b.Subscribe(bus.Decorate(handler, logging, timing))
+	assert.NoError(t, err)
+
+	err = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"timing", "logging", "handler"}, calls)
+	assert.GreaterOrEqual(t, elapsed, 10*time.Millisecond)
+}
+
+func TestDecorate_NoDecorators(t *testing.T) {
+	handler := func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	}
+
+	decorated := bus.Decorate(handler)
+
+	assert.Equal(t, reflect.ValueOf(handler).Pointer(), reflect.ValueOf(decorated).Pointer())
+}
@@ -0,0 +1,96 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DependencyGraph records which handlers publish messages that are handled by other handlers. An edge from handler A
+// to handler B means A published a message, using the context it was invoked with, that was dispatched to B.
+type DependencyGraph struct {
+	mu    sync.Mutex
+	edges map[Edge]struct{}
+	bus   *eventBus
+}
+
+// Edge is a dependency between two handlers, identified by their fully qualified function names
+type Edge struct {
+	From string
+	To   string
+}
+
+// BeginTracking starts recording the dependencies between the handlers of b. Call StopTracking to stop recording.
+// Tracking adds a context value for each handler invocation so should be used for diagnostics rather than left
+// enabled in production.
+func BeginTracking(b Bus) *DependencyGraph {
+	g := &DependencyGraph{edges: make(map[Edge]struct{})}
+	if e, ok := b.(*eventBus); ok {
+		g.bus = e
+		e.dependencies.Store(g)
+	}
+	return g
+}
+
+// StopTracking stops recording dependencies. The graph retains the edges recorded so far
+func StopTracking(g *DependencyGraph) {
+	if g.bus != nil {
+		g.bus.dependencies.CompareAndSwap(g, (*DependencyGraph)(nil))
+	}
+}
+
+// Edges returns the recorded edges ordered by From and then To
+func (g *DependencyGraph) Edges() []Edge {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	edges := make([]Edge, 0, len(g.edges))
+	for edge := range g.edges {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	return edges
+}
+
+// DOT returns the graph in the Graphviz DOT format
+func (g *DependencyGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph bus {\n")
+	for _, edge := range g.Edges() {
+		sb.WriteString(fmt.Sprintf("  %q -> %q;\n", edge.From, edge.To))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func (g *DependencyGraph) add(edge Edge) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.edges[edge] = struct{}{}
+}
+
+// invokingHandlerKey is the context key holding the name of the handler being invoked while tracking is enabled
+type invokingHandlerKey struct{}
+
+// track records the dependency between the handler that published the message, if any, and the given handler. It
+// returns the params with a context identifying the handler so that messages it publishes can be tracked. The params
+// are returned unchanged if tracking is disabled
+func (e *eventBus) track(handler handler, params []reflect.Value) []reflect.Value {
+	g, _ := e.dependencies.Load().(*DependencyGraph)
+	if g == nil {
+		return params
+	}
+	ctx := params[0].Interface().(context.Context)
+	if from, ok := ctx.Value(invokingHandlerKey{}).(string); ok {
+		g.add(Edge{From: from, To: handler.name})
+	}
+	ctx = context.WithValue(ctx, invokingHandlerKey{}, handler.name)
+	return []reflect.Value{reflect.ValueOf(ctx), params[1]}
+}
@@ -0,0 +1,60 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type OrderPlaced struct {
+	ID string
+}
+
+type SendReceipt struct {
+	OrderID string
+}
+
+var dependencyBus bus.Bus
+
+func placeOrderHandler(ctx context.Context, event OrderPlaced) error {
+	return dependencyBus.Publish(ctx, SendReceipt{OrderID: event.ID})
+}
+
+func sendReceiptHandler(ctx context.Context, command SendReceipt) error {
+	return nil
+}
+
+func TestBeginTracking(t *testing.T) {
+	dependencyBus = bus.New()
+	_ = dependencyBus.Subscribe(placeOrderHandler)
+	_ = dependencyBus.Subscribe(sendReceiptHandler)
+
+	graph := bus.BeginTracking(dependencyBus)
+	err := dependencyBus.Publish(context.Background(), OrderPlaced{ID: "1234"})
+	bus.StopTracking(graph)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []bus.Edge{{
+		From: "github.com/steinfletcher/bus_test.placeOrderHandler",
+		To:   "github.com/steinfletcher/bus_test.sendReceiptHandler",
+	}}, graph.Edges())
+	assert.Equal(t, `digraph bus {
+  "github.com/steinfletcher/bus_test.placeOrderHandler" -> "github.com/steinfletcher/bus_test.sendReceiptHandler";
+}
+`, graph.DOT())
+}
+
+func TestStopTracking(t *testing.T) {
+	dependencyBus = bus.New()
+	_ = dependencyBus.Subscribe(placeOrderHandler)
+	_ = dependencyBus.Subscribe(sendReceiptHandler)
+
+	graph := bus.BeginTracking(dependencyBus)
+	bus.StopTracking(graph)
+	err := dependencyBus.Publish(context.Background(), OrderPlaced{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, graph.Edges())
+}
@@ -0,0 +1,168 @@
+// Package docgen generates Go source code that reproduces the subscriptions of a bus. The output is useful for
+// documenting how a bus is wired and as scaffolding for tests.
+package docgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/steinfletcher/bus"
+)
+
+// PackageName is the package name of the generated source
+const PackageName = "registration"
+
+// GenerateRegistrationCode returns gofmt'd Go source declaring a RegisterHandlers function that subscribes each
+// handler subscribed on b by name, with the Subscribe method matching the kind of the subscription. Handlers that
+// cannot be referenced by name, such as function literals, method values and functions declared in main or external
+// test packages, are listed in a comment instead, as are raw handlers whose codec is not the zero value of an exported
+// struct type.
+func GenerateRegistrationCode(b bus.Inspector) (string, error) {
+	imports := newImports()
+	var body, skipped bytes.Buffer
+	for _, subscription := range b.Subscriptions() {
+		importPath, funcName, ok := splitHandlerName(subscription.HandlerName)
+		if !ok {
+			fmt.Fprintf(&skipped, "//\t%s\n", subscription.HandlerName)
+			continue
+		}
+		handler := imports.Alias(importPath) + "." + funcName
+		var call string
+		switch {
+		case subscription.Kind == bus.KindTopic:
+			call = fmt.Sprintf("b.SubscribeTopic(%s, %s)", strconv.Quote(subscription.Topic), handler)
+		case subscription.Kind == bus.KindRequest:
+			call = fmt.Sprintf("b.SubscribeRequest(%s)", handler)
+		case subscription.Kind == bus.KindRaw:
+			codec, ok := codecLiteral(imports, subscription.Codec)
+			if !ok {
+				fmt.Fprintf(&skipped, "//\t%s\n", subscription.HandlerName)
+				continue
+			}
+			call = fmt.Sprintf("b.SubscribeRaw(%s, %s, %s)", strconv.Quote(subscription.RawMessageType), codec, handler)
+		case subscription.Tenant != "":
+			call = fmt.Sprintf("b.SubscribeTenant(%s, %s)", strconv.Quote(subscription.Tenant), handler)
+		case subscription.Async:
+			call = fmt.Sprintf("b.SubscribeAsync(%s)", handler)
+		default:
+			call = fmt.Sprintf("b.Subscribe(%s)", handler)
+		}
+		fmt.Fprintf(&body, "if err := %s; err != nil {\nreturn err\n}\n", call)
+	}
+
+	var src bytes.Buffer
+	src.WriteString("// Code generated by docgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&src, "package %s\n\n", PackageName)
+	src.WriteString("import (\n\"github.com/steinfletcher/bus\"\n")
+	for _, imp := range imports.paths {
+		fmt.Fprintf(&src, "%s %s\n", imports.aliases[imp], strconv.Quote(imp))
+	}
+	src.WriteString(")\n\n")
+	src.WriteString("// RegisterHandlers subscribes the message handlers to b\n")
+	if skipped.Len() > 0 {
+		src.WriteString("//\n// The following handlers cannot be subscribed by the generated code and must be subscribed manually\n//\n")
+		src.Write(skipped.Bytes())
+	}
+	src.WriteString("func RegisterHandlers(b bus.Bus) error {\n")
+	src.Write(body.Bytes())
+	src.WriteString("return nil\n}\n")
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return string(formatted), nil
+}
+
+// splitHandlerName splits a fully qualified function name such as github.com/org/pkg.Handler into its import path
+// and function name. It returns false for names that cannot be referenced from another package
+func splitHandlerName(name string) (string, string, bool) {
+	slash := strings.LastIndex(name, "/")
+	dot := strings.Index(name[slash+1:], ".")
+	if dot < 0 {
+		return "", "", false
+	}
+	dot += slash + 1
+	importPath, funcName := name[:dot], name[dot+1:]
+	if !isExportedIdentifier(funcName) || !importable(importPath) {
+		return "", "", false
+	}
+	return importPath, funcName, true
+}
+
+// busPackagePath is the import path of package bus, which the generated code imports as bus
+var busPackagePath = reflect.TypeOf(bus.JSONCodec{}).PkgPath()
+
+// codecLiteral returns a composite literal of codec, e.g. bus.JSONCodec{}. It returns false if codec is not the zero
+// value of an exported struct type
+func codecLiteral(imports *imports, codec bus.Codec) (string, bool) {
+	if codec == nil {
+		return "", false
+	}
+	t := reflect.TypeOf(codec)
+	if t.Kind() != reflect.Struct || !isExportedIdentifier(t.Name()) || !importable(t.PkgPath()) || !reflect.ValueOf(codec).IsZero() {
+		return "", false
+	}
+	if t.PkgPath() == busPackagePath {
+		return "bus." + t.Name() + "{}", true
+	}
+	return imports.Alias(t.PkgPath()) + "." + t.Name() + "{}", true
+}
+
+// importable reports whether the package with the given path, as it appears in function names, can be imported.
+// External test packages have the path of the package under test with a _test suffix and main packages the path main
+func importable(importPath string) bool {
+	return importPath != "main" && !strings.HasSuffix(importPath, "_test")
+}
+
+func isExportedIdentifier(s string) bool {
+	for i, r := range s {
+		if i == 0 && !unicode.IsUpper(r) {
+			return false
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// imports assigns a unique alias to each imported package
+type imports struct {
+	paths   []string
+	aliases map[string]string
+	used    map[string]bool
+}
+
+func newImports() *imports {
+	return &imports{
+		aliases: make(map[string]string),
+		used:    map[string]bool{"bus": true, "b": true},
+	}
+}
+
+func (i *imports) Alias(importPath string) string {
+	if alias, ok := i.aliases[importPath]; ok {
+		return alias
+	}
+	base := importPath[strings.LastIndex(importPath, "/")+1:]
+	base = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, base)
+	alias := base
+	for n := 2; i.used[alias]; n++ {
+		alias = fmt.Sprintf("%s%d", base, n)
+	}
+	i.used[alias] = true
+	i.aliases[importPath] = alias
+	i.paths = append(i.paths, importPath)
+	return alias
+}
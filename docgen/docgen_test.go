@@ -0,0 +1,127 @@
+package docgen_test
+
+import (
+	"context"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/docgen"
+	"github.com/steinfletcher/bus/docgen/internal/handlers"
+	"github.com/stretchr/testify/assert"
+)
+
+func ListUsersHandler(ctx context.Context, query *handlers.GetUserQuery) error {
+	return nil
+}
+
+func TestGenerateRegistrationCode(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(handlers.GetUserHandler)
+	_ = b.SubscribeAsync(handlers.AuditUserCreated)
+	_ = b.SubscribeTenant("tenant-A", handlers.GetUserHandler)
+	_ = b.Subscribe(func(ctx context.Context, event handlers.UserCreated) error { return nil })
+	_ = b.Subscribe(ListUsersHandler)
+
+	code, err := docgen.GenerateRegistrationCode(b)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by docgen. DO NOT EDIT.
+
+package registration
+
+import (
+	"github.com/steinfletcher/bus"
+	handlers "github.com/steinfletcher/bus/docgen/internal/handlers"
+)
+
+// RegisterHandlers subscribes the message handlers to b
+//
+// The following handlers cannot be subscribed by the generated code and must be subscribed manually
+//
+//	github.com/steinfletcher/bus/docgen_test.ListUsersHandler
+//	github.com/steinfletcher/bus/docgen_test.TestGenerateRegistrationCode.func1
+func RegisterHandlers(b bus.Bus) error {
+	if err := b.Subscribe(handlers.GetUserHandler); err != nil {
+		return err
+	}
+	if err := b.SubscribeAsync(handlers.AuditUserCreated); err != nil {
+		return err
+	}
+	if err := b.SubscribeTenant("tenant-A", handlers.GetUserHandler); err != nil {
+		return err
+	}
+	return nil
+}
+`, code)
+	assert.NoError(t, typeCheck(code))
+}
+
+type xmlCodec struct{}
+
+func (c *xmlCodec) Unmarshal(data []byte, v interface{}) error {
+	return nil
+}
+
+func TestGenerateRegistrationCode_Kinds(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeTopic("users.*", handlers.GetUserHandler)
+	_ = b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, handlers.GetUserHandler)
+	_ = b.SubscribeRaw("GetUserQueryXML", &xmlCodec{}, handlers.GetUserHandler)
+	_ = b.SubscribeRequest(handlers.GetUserName)
+
+	code, err := docgen.GenerateRegistrationCode(b)
+
+	assert.NoError(t, err)
+	assert.Equal(t, `// Code generated by docgen. DO NOT EDIT.
+
+package registration
+
+import (
+	"github.com/steinfletcher/bus"
+	handlers "github.com/steinfletcher/bus/docgen/internal/handlers"
+)
+
+// RegisterHandlers subscribes the message handlers to b
+//
+// The following handlers cannot be subscribed by the generated code and must be subscribed manually
+//
+//	github.com/steinfletcher/bus/docgen/internal/handlers.GetUserHandler
+func RegisterHandlers(b bus.Bus) error {
+	if err := b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, handlers.GetUserHandler); err != nil {
+		return err
+	}
+	if err := b.SubscribeRequest(handlers.GetUserName); err != nil {
+		return err
+	}
+	if err := b.SubscribeTopic("users.*", handlers.GetUserHandler); err != nil {
+		return err
+	}
+	return nil
+}
+`, code)
+	assert.NoError(t, typeCheck(code))
+}
+
+func TestGenerateRegistrationCode_NoHandlers(t *testing.T) {
+	code, err := docgen.GenerateRegistrationCode(bus.New())
+
+	assert.NoError(t, err)
+	assert.NoError(t, typeCheck(code))
+}
+
+// typeCheck parses and type checks the generated code, importing packages from source
+func typeCheck(code string) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "registration.go", code, parser.AllErrors)
+	if err != nil {
+		return err
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check(docgen.PackageName, fset, []*ast.File{file}, nil)
+	return err
+}
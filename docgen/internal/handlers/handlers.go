@@ -0,0 +1,23 @@
+// Package handlers declares message handlers in an importable package, so that the code generated for them by docgen
+// can be type checked in tests.
+package handlers
+
+import "context"
+
+type GetUserQuery struct {
+	ID string
+}
+
+type UserCreated struct {
+	ID string
+}
+
+func GetUserHandler(ctx context.Context, query *GetUserQuery) error {
+	return nil
+}
+
+func AuditUserCreated(ctx context.Context, event UserCreated) {}
+
+func GetUserName(ctx context.Context, query *GetUserQuery) (string, error) {
+	return query.ID, nil
+}
@@ -0,0 +1,52 @@
+package bus
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+)
+
+// ErrAlreadySubscribed is returned when a function is subscribed more than once for the same message type, see
+// WithDuplicateSubscriptionCheck
+var ErrAlreadySubscribed = errors.New("handler already subscribed")
+
+// WithDuplicateSubscriptionCheck detects a function being subscribed more than once for the same message type and
+// tenant by comparing function pointers. A duplicate subscription returns an error wrapping ErrAlreadySubscribed, or
+// if ignore is true it is skipped and the subscription of the existing handler is returned. Closures created by the
+// same function literal share a function pointer, so they are reported as duplicates of each other. Method values
+// created with reflect and functions created with reflect.MakeFunc, as subscribed by AutoSubscribeByConvention, are
+// never reported as duplicates because every such function shares a function pointer.
+func WithDuplicateSubscriptionCheck(ignore bool) Option {
+	return func(e *eventBus) {
+		e.duplicates = &duplicateCheck{ignore: ignore}
+	}
+}
+
+// duplicateCheck configures the handling of duplicate subscriptions
+type duplicateCheck struct {
+	ignore bool
+}
+
+// sharedCode names the functions whose code backs every function value of a kind, so that their function pointers
+// are all equal
+var sharedCode = map[string]bool{
+	// method values created with reflect.Value.Method
+	"reflect.methodValueCall": true,
+	// functions created with reflect.MakeFunc
+	"reflect.makeFuncStub": true,
+}
+
+// identifiable reports whether fn can be told apart from other functions by its function pointer
+func identifiable(fn interface{}) bool {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	return f == nil || !sharedCode[f.Name()]
+}
+
+// sameFunc reports whether a and b were subscribed with the same function. Functions that are not identifiable are
+// never the same
+func sameFunc(a, b handler) bool {
+	if a.ephemeral || b.ephemeral || reflect.ValueOf(a.source).Pointer() != reflect.ValueOf(b.source).Pointer() {
+		return false
+	}
+	return identifiable(a.source)
+}
@@ -0,0 +1,78 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDuplicateSubscriptionCheck(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDuplicateSubscriptionCheck(false))
+	assert.NoError(t, b.Subscribe(getUserHandler))
+
+	err := b.SubscribeAsync(getUserHandler)
+
+	assert.True(t, errors.Is(err, bus.ErrAlreadySubscribed))
+	assert.EqualError(t, err, "handler already subscribed: github.com/steinfletcher/bus_test.getUserHandler for '*bus_test.GetUserQuery'")
+	assert.Len(t, b.Subscriptions(), 1)
+}
+
+func TestWithDuplicateSubscriptionCheck_Ignore(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDuplicateSubscriptionCheck(true))
+	first, err := b.SubscribeScheduled(getUserHandler, nil)
+	assert.NoError(t, err)
+
+	second, err := b.SubscribeScheduled(getUserHandler, nil)
+
+	assert.NoError(t, err)
+	assert.Len(t, b.Subscriptions(), 1)
+	assert.NoError(t, second.Unsubscribe())
+	assert.Equal(t, bus.ErrSubscriptionNotFound, first.Unsubscribe())
+}
+
+func TestWithDuplicateSubscriptionCheck_DistinctKeys(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDuplicateSubscriptionCheck(false))
+	assert.NoError(t, b.Subscribe(getUserHandler))
+
+	assert.NoError(t, b.SubscribeTenant("acme", getUserHandler))
+	assert.NoError(t, b.Subscribe(failingHandler))
+	assert.Len(t, b.Subscriptions(), 3)
+}
+
+func TestWithDuplicateSubscriptionCheck_AllowsAsk(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDuplicateSubscriptionCheck(false))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return b.Publish(ctx, &GetUserReply{ID: query.ID})
+	})
+
+	for i := 0; i < 2; i++ {
+		reply, err := bus.AskWithTimeout[GetUserReply](context.Background(), b, &GetUserQuery{ID: "1234"}, time.Second)
+		assert.NoError(t, err)
+		assert.Equal(t, "1234", reply.ID)
+	}
+}
+
+func TestWithDuplicateSubscriptionCheck_ReflectFuncs(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDuplicateSubscriptionCheck(false))
+
+	n, err := bus.AutoSubscribeByConvention(b, &userService{}, "On", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	n, err = bus.AutoSubscribeByConvention(b, &userService{}, "On", false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.Len(t, b.Subscriptions(), 4)
+}
+
+func TestSubscribe_AllowsDuplicatesByDefault(t *testing.T) {
+	b := bus.New()
+	assert.NoError(t, b.Subscribe(getUserHandler))
+
+	assert.NoError(t, b.Subscribe(getUserHandler))
+	assert.Len(t, b.Subscriptions(), 2)
+}
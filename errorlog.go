@@ -0,0 +1,39 @@
+package bus
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+)
+
+// WithHandlerErrorLogger sets logger to be called each time a handler returns an error, including errors from async
+// handlers and from attempts that are retried. file and line locate the subscribed handler function in the source, so
+// the handler can be found from the log without knowing which package registered it.
+func WithHandlerErrorLogger(logger func(msgType, handlerName, file string, line int, err error)) Option {
+	return func(e *eventBus) {
+		e.errorLogger = logger
+	}
+}
+
+// logHandlerError calls the handler error logger with the source location of the handler
+func (e *eventBus) logHandlerError(handler handler, err error) {
+	if e.errorLogger == nil {
+		return
+	}
+	var file string
+	var line int
+	if f := runtime.FuncForPC(reflect.ValueOf(handler.source).Pointer()); f != nil {
+		file, line = f.FileLine(f.Entry())
+	}
+	e.errorLogger(handler.messageType().String(), handler.name, file, line, err)
+}
+
+// WithAsyncErrorHandler sets fn to be called with the context, the message and the error each time an async handler
+// returns an error or panics, see WithPanicRecovery. The error is mapped by the function set with WithErrorMapper
+// first. fn is called in the go routine of the async handler, so a slow fn delays the following messages of the
+// handler. Errors are passed to fn in addition to being dead lettered.
+func WithAsyncErrorHandler(fn func(ctx context.Context, msg Message, err error)) Option {
+	return func(e *eventBus) {
+		e.asyncErrorHandler = fn
+	}
+}
@@ -0,0 +1,112 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type loggedError struct {
+	msgType     string
+	handlerName string
+	file        string
+	line        int
+	err         error
+}
+
+func failingHandler(ctx context.Context, query *GetUserQuery) error {
+	return errors.New("user not found")
+}
+
+func newLoggingBus(logged chan<- loggedError) bus.Bus {
+	return bus.NewWithOptions(bus.WithHandlerErrorLogger(func(msgType, handlerName, file string, line int, err error) {
+		logged <- loggedError{msgType: msgType, handlerName: handlerName, file: file, line: line, err: err}
+	}))
+}
+
+func TestBus_WithHandlerErrorLogger(t *testing.T) {
+	logged := make(chan loggedError, 1)
+	b := newLoggingBus(logged)
+	_ = b.Subscribe(failingHandler)
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.EqualError(t, err, "user not found")
+	entry := <-logged
+	assert.Equal(t, "*bus_test.GetUserQuery", entry.msgType)
+	assert.Equal(t, "github.com/steinfletcher/bus_test.failingHandler", entry.handlerName)
+	assert.Equal(t, "errorlog_test.go", filepath.Base(entry.file))
+	assert.Equal(t, 22, entry.line)
+	assert.EqualError(t, entry.err, "user not found")
+}
+
+func TestBus_WithHandlerErrorLogger_Async(t *testing.T) {
+	logged := make(chan loggedError, 1)
+	b := newLoggingBus(logged)
+	_ = b.SubscribeAsync(failingHandler)
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	entry := <-logged
+	assert.Equal(t, "errorlog_test.go", filepath.Base(entry.file))
+	assert.EqualError(t, entry.err, "user not found")
+}
+
+func TestBus_WithHandlerErrorLogger_WrappedHandler(t *testing.T) {
+	logged := make(chan loggedError, 1)
+	b := newLoggingBus(logged)
+	_ = b.SubscribeWithAdvisoryTimeout(failingHandler, time.Second, func(string, time.Duration) {})
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	entry := <-logged
+	assert.Equal(t, "github.com/steinfletcher/bus_test.failingHandler", entry.handlerName)
+	assert.Equal(t, "errorlog_test.go", filepath.Base(entry.file))
+	assert.Equal(t, 22, entry.line)
+}
+
+type asyncError struct {
+	msg bus.Message
+	err error
+}
+
+func TestBus_WithAsyncErrorHandler(t *testing.T) {
+	errs := make(chan asyncError, 1)
+	b := bus.NewWithOptions(bus.WithAsyncErrorHandler(func(ctx context.Context, msg bus.Message, err error) {
+		errs <- asyncError{msg: msg, err: err}
+	}))
+	_ = b.SubscribeAsync(failingHandler)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	failed := <-errs
+	assert.Equal(t, &GetUserQuery{ID: "1234"}, failed.msg)
+	assert.EqualError(t, failed.err, "user not found")
+}
+
+func TestBus_WithAsyncErrorHandler_Panic(t *testing.T) {
+	errs := make(chan asyncError, 1)
+	b := bus.NewWithOptions(bus.WithAsyncErrorHandler(func(ctx context.Context, msg bus.Message, err error) {
+		errs <- asyncError{msg: msg, err: err}
+	}))
+	_ = b.SubscribeAsync(panickingHandler)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	var panicErr *bus.PanicError
+	assert.ErrorAs(t, (<-errs).err, &panicErr)
+}
+
+func TestBus_WithAsyncErrorHandler_SyncErrorsNotHandled(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithAsyncErrorHandler(func(ctx context.Context, msg bus.Message, err error) {
+		t.Error("async error handler called")
+	}))
+	_ = b.Subscribe(failingHandler)
+
+	assert.EqualError(t, b.Publish(context.Background(), &GetUserQuery{}), "user not found")
+}
@@ -1,6 +1,6 @@
 module github.com/steinfletcher/bus/example
 
-go 1.16
+go 1.21
 
 replace github.com/steinfletcher/bus => ../
 
@@ -9,3 +9,17 @@ require (
 	github.com/steinfletcher/apitest v1.5.11
 	github.com/steinfletcher/bus v0.0.0-00010101000000-000000000000
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/labstack/gommon v0.3.0 // indirect
+	github.com/mattn/go-colorable v0.1.8 // indirect
+	github.com/mattn/go-isatty v0.0.12 // indirect
+	github.com/valyala/bytebufferpool v1.0.0 // indirect
+	github.com/valyala/fasttemplate v1.2.1 // indirect
+	golang.org/x/crypto v0.0.0-20210322153248-0c34fe9e7dc2 // indirect
+	golang.org/x/net v0.0.0-20210405180319-a5a99cb37ef4 // indirect
+	golang.org/x/sys v0.0.0-20210403161142-5e06dd20ab57 // indirect
+	golang.org/x/text v0.3.6 // indirect
+	golang.org/x/time v0.5.0 // indirect
+)
@@ -0,0 +1,17 @@
+package bus
+
+import "errors"
+
+// ErrMultipleHandlers is returned when subscribing a second handler for a message type whose handler must be the only
+// one, see WithSingleHandler
+var ErrMultipleHandlers = errors.New("message type already has a handler")
+
+// WithSingleHandler enforces the handler to be the only handler of its message type, as commands and queries require.
+// Subscribing the handler returns an error wrapping ErrMultipleHandlers if the message type already has a handler,
+// and so does subscribing another handler for the message type while the handler is subscribed. Handlers subscribed
+// for a tenant, see SubscribeTenant, are counted separately from the handlers subscribed for all tenants.
+func WithSingleHandler() SubscribeOption {
+	return func(h *handler) {
+		h.exclusive = true
+	}
+}
@@ -0,0 +1,41 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_WithSingleHandler(t *testing.T) {
+	b := bus.New()
+	err := b.Subscribe(func(ctx context.Context, cmd *SomeCommand) error { return nil }, bus.WithSingleHandler())
+	assert.NoError(t, err)
+
+	err = b.SubscribeAsync(func(ctx context.Context, cmd *SomeCommand) {})
+
+	assert.True(t, errors.Is(err, bus.ErrMultipleHandlers))
+	assert.Len(t, b.Subscriptions(), 1)
+}
+
+func TestBus_WithSingleHandlerRejectsExistingHandler(t *testing.T) {
+	b := bus.New()
+	assert.NoError(t, b.Subscribe(func(ctx context.Context, cmd *SomeCommand) error { return nil }))
+
+	err := b.Subscribe(func(ctx context.Context, cmd *SomeCommand) error { return nil }, bus.WithSingleHandler())
+
+	assert.True(t, errors.Is(err, bus.ErrMultipleHandlers))
+}
+
+func TestBus_WithSingleHandlerAfterUnsubscribe(t *testing.T) {
+	b := bus.New()
+	sub, err := b.SubscribeHandle(func(ctx context.Context, cmd *SomeCommand) error { return nil }, bus.WithSingleHandler())
+	assert.NoError(t, err)
+	assert.NoError(t, sub.Unsubscribe())
+
+	err = b.Subscribe(func(ctx context.Context, cmd *SomeCommand) error { return nil })
+
+	assert.NoError(t, err)
+}
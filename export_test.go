@@ -0,0 +1,24 @@
+package bus
+
+import "time"
+
+// RateMeter exposes rateMeter to the external tests
+type RateMeter = rateMeter
+
+// NewRateMeterWithClock exposes newRateMeterWithClock to the external tests
+func NewRateMeterWithClock(now func() time.Time) *RateMeter {
+	return newRateMeterWithClock(now)
+}
+
+// WithPublishRateAlertTicks exposes withPublishRateAlert to the external tests
+func WithPublishRateAlertTicks(minRPS float64, alertFn func(currentRPS float64), interval time.Duration, ticks <-chan time.Time) Option {
+	return withPublishRateAlert(minRPS, alertFn, interval, ticks)
+}
+
+// AsyncQueue exposes asyncQueue to the external tests
+type AsyncQueue = asyncQueue
+
+// NewAsyncQueue exposes newAsyncQueue to the external tests
+func NewAsyncQueue(size int, policy BackpressurePolicy) *AsyncQueue {
+	return newAsyncQueue(size, policy)
+}
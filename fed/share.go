@@ -0,0 +1,71 @@
+// Package fed shares the subscriptions of a bus with a registry in a network of buses, so that remote publishers can
+// discover which buses handle a message type.
+package fed
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/steinfletcher/bus"
+)
+
+// RemoteBus is the registry of a bus network that receives the subscription manifest of a local bus
+type RemoteBus interface {
+	PublishSubscriptions(manifest SubscriptionManifest) error
+}
+
+// SubscriptionManifest describes the message types handled by a bus
+type SubscriptionManifest struct {
+	// Subscriptions are ordered by message type name
+	Subscriptions []MessageSubscriptions `json:"subscriptions"`
+}
+
+// MessageSubscriptions is the number of handlers subscribed to a message type
+type MessageSubscriptions struct {
+	MessageType string `json:"messageType"`
+	Handlers    int    `json:"handlers"`
+}
+
+// Manifest returns the subscription manifest of local. Only handlers that receive messages published by type are
+// counted, handlers subscribed with SubscribeTopic, SubscribeRaw and SubscribeRequest are left out
+func Manifest(local bus.Inspector) SubscriptionManifest {
+	counts := map[string]int{}
+	for _, subscription := range local.Subscriptions() {
+		if subscription.Kind != bus.KindType {
+			continue
+		}
+		counts[subscription.MessageType.String()]++
+	}
+	manifest := SubscriptionManifest{Subscriptions: make([]MessageSubscriptions, 0, len(counts))}
+	for msgType, handlers := range counts {
+		manifest.Subscriptions = append(manifest.Subscriptions, MessageSubscriptions{MessageType: msgType, Handlers: handlers})
+	}
+	sort.Slice(manifest.Subscriptions, func(i, j int) bool {
+		return manifest.Subscriptions[i].MessageType < manifest.Subscriptions[j].MessageType
+	})
+	return manifest
+}
+
+// ShareSubscriptions publishes the subscription manifest of local to remote
+func ShareSubscriptions(local bus.Inspector, remote RemoteBus) error {
+	return remote.PublishSubscriptions(Manifest(local))
+}
+
+// ShareSubscriptionsEvery calls ShareSubscriptions immediately and then once per interval until ctx is done, so that
+// remote reflects subscriptions added and removed at runtime. onError is called with each failed sync, which is
+// retried at the next interval. It blocks until ctx is done.
+func ShareSubscriptionsEvery(ctx context.Context, local bus.Inspector, remote RemoteBus, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := ShareSubscriptions(local, remote); err != nil && onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
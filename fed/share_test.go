@@ -0,0 +1,103 @@
+package fed_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/fed"
+	"github.com/stretchr/testify/assert"
+)
+
+type GetUserQuery struct {
+	ID string
+}
+
+type OrderPlaced struct {
+	ID string
+}
+
+type registry struct {
+	mu       sync.Mutex
+	manifest fed.SubscriptionManifest
+	syncs    int
+	err      error
+}
+
+func (r *registry) PublishSubscriptions(manifest fed.SubscriptionManifest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.syncs++
+	if r.err != nil {
+		return r.err
+	}
+	r.manifest = manifest
+	return nil
+}
+
+func (r *registry) Syncs() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.syncs
+}
+
+func getUserHandler(ctx context.Context, query *GetUserQuery) error {
+	return nil
+}
+
+func orderPlacedHandler(ctx context.Context, event *OrderPlaced) {}
+
+func TestShareSubscriptions(t *testing.T) {
+	local := bus.New()
+	remote := &registry{}
+	_ = local.Subscribe(getUserHandler)
+
+	assert.NoError(t, fed.ShareSubscriptions(local, remote))
+	assert.Equal(t, fed.SubscriptionManifest{Subscriptions: []fed.MessageSubscriptions{
+		{MessageType: "*fed_test.GetUserQuery", Handlers: 1},
+	}}, remote.manifest)
+
+	_ = local.SubscribeAsync(orderPlacedHandler)
+	_ = local.SubscribeTenant("acme", getUserHandler)
+	_ = local.SubscribeTopic("users.*", getUserHandler)
+
+	assert.NoError(t, fed.ShareSubscriptions(local, remote))
+	assert.Equal(t, fed.SubscriptionManifest{Subscriptions: []fed.MessageSubscriptions{
+		{MessageType: "*fed_test.GetUserQuery", Handlers: 2},
+		{MessageType: "*fed_test.OrderPlaced", Handlers: 1},
+	}}, remote.manifest)
+}
+
+func TestShareSubscriptions_RemoteError(t *testing.T) {
+	remote := &registry{err: errors.New("registry unavailable")}
+
+	err := fed.ShareSubscriptions(bus.New(), remote)
+
+	assert.EqualError(t, err, "registry unavailable")
+}
+
+func TestShareSubscriptionsEvery(t *testing.T) {
+	remote := &registry{err: errors.New("registry unavailable")}
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 10)
+	done := make(chan struct{})
+
+	go func() {
+		fed.ShareSubscriptionsEvery(ctx, bus.New(), remote, 10*time.Millisecond, func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+		close(done)
+	}()
+	time.Sleep(35 * time.Millisecond)
+	cancel()
+	<-done
+
+	assert.GreaterOrEqual(t, remote.Syncs(), 3)
+	assert.EqualError(t, <-errs, "registry unavailable")
+}
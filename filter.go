@@ -0,0 +1,44 @@
+package bus
+
+import "context"
+
+// MessageFilter is called with each published message before its handlers are looked up. If allow is false the
+// message is discarded and Publish returns nil without calling any handler. Otherwise the message is replaced by
+// mutatedMsg, which may be of a different type, before it is passed to the next filter. A nil mutatedMsg leaves the
+// message unchanged. Filters are not applied to messages published with PublishRaw.
+type MessageFilter func(ctx context.Context, msg Message) (allow bool, mutatedMsg Message)
+
+func (e *eventBus) AddFilter(f MessageFilter) {
+	e.filtersMu.Lock()
+	defer e.filtersMu.Unlock()
+	e.filters = append(e.filters, f)
+}
+
+// filter runs the filters and returns the message to publish, or false if a filter discarded it
+func (e *eventBus) filter(ctx context.Context, msg Message) (Message, bool) {
+	e.filtersMu.RLock()
+	defer e.filtersMu.RUnlock()
+	for _, f := range e.filters {
+		allow, mutated := f(ctx, msg)
+		if !allow {
+			return nil, false
+		}
+		if mutated != nil {
+			msg = mutated
+		}
+	}
+	return msg, true
+}
+
+// WithFilter only passes the handler the messages for which fn returns true, e.g. orders above an amount. Messages
+// are filtered before they are queued for an async handler, so rejected messages do not occupy its queue. Unlike
+// AddFilter, fn applies to this handler only and cannot modify the message. A message rejected by every handler is
+// not dead lettered and Publish returns nil
+func WithFilter(fn func(msg Message) bool) SubscribeOption {
+	return func(h *handler) {
+		accept := h.accept
+		h.accept = func(msg Message) bool {
+			return (accept == nil || accept(msg)) && fn(msg)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_AddFilter(t *testing.T) {
+	b := bus.New()
+	b.AddFilter(func(ctx context.Context, msg bus.Message) (bool, bus.Message) {
+		if query, ok := msg.(*GetUserQuery); ok {
+			enriched := *query
+			enriched.Result = UserResult{Email: query.ID + "@example.com"}
+			return true, &enriched
+		}
+		return true, nil
+	})
+	b.AddFilter(func(ctx context.Context, msg bus.Message) (bool, bus.Message) {
+		_, blocked := msg.(*SomeCommand)
+		return !blocked, nil
+	})
+	var queries []*GetUserQuery
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		queries = append(queries, query)
+		return nil
+	})
+	var commands int
+	_ = b.Subscribe(func(ctx context.Context, cmd *SomeCommand) error {
+		commands++
+		return nil
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "jan"}))
+	assert.NoError(t, b.Publish(context.Background(), &SomeCommand{}))
+
+	assert.Equal(t, []*GetUserQuery{{ID: "jan", Result: UserResult{Email: "jan@example.com"}}}, queries)
+	assert.Zero(t, commands)
+}
+
+func TestBus_AddFilter_ReplacesMessageType(t *testing.T) {
+	b := bus.New()
+	b.AddFilter(func(ctx context.Context, msg bus.Message) (bool, bus.Message) {
+		if query, ok := msg.(GetUserQuery); ok {
+			return true, &query
+		}
+		return true, nil
+	})
+	var received *GetUserQuery
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		received = query
+		return nil
+	})
+
+	err := b.PublishSync(context.Background(), GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &GetUserQuery{ID: "1234"}, received)
+}
+
+type PaymentReceived struct {
+	Amount int
+}
+
+func TestBus_WithFilter(t *testing.T) {
+	b := bus.New()
+	var large, all []int
+	_ = b.Subscribe(func(ctx context.Context, payment *PaymentReceived) error {
+		large = append(large, payment.Amount)
+		return nil
+	}, bus.WithFilter(func(msg bus.Message) bool {
+		return msg.(*PaymentReceived).Amount > 1000
+	}))
+	_ = b.Subscribe(func(ctx context.Context, payment *PaymentReceived) error {
+		all = append(all, payment.Amount)
+		return nil
+	})
+
+	for _, amount := range []int{500, 1500, 2500} {
+		assert.NoError(t, b.Publish(context.Background(), &PaymentReceived{Amount: amount}))
+	}
+
+	assert.Equal(t, []int{1500, 2500}, large)
+	assert.Equal(t, []int{500, 1500, 2500}, all)
+}
+
+func TestBus_WithFilter_Async(t *testing.T) {
+	b := bus.New()
+	received := make(chan int, 3)
+	_ = b.SubscribeAsync(func(ctx context.Context, payment *PaymentReceived) {
+		received <- payment.Amount
+	}, bus.WithFilter(func(msg bus.Message) bool {
+		return msg.(*PaymentReceived).Amount > 1000
+	}))
+
+	for _, amount := range []int{500, 1500} {
+		assert.NoError(t, b.Publish(context.Background(), &PaymentReceived{Amount: amount}))
+	}
+	assert.NoError(t, b.Close(context.Background()))
+	close(received)
+
+	var amounts []int
+	for amount := range received {
+		amounts = append(amounts, amount)
+	}
+	assert.Equal(t, []int{1500}, amounts)
+}
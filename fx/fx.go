@@ -0,0 +1,53 @@
+// Package fx subscribes handlers constructed by an fx application to a message bus, so that handlers can declare
+// their dependencies as constructor parameters rather than being wired by hand.
+package fx
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/steinfletcher/bus"
+	uberfx "go.uber.org/fx"
+)
+
+// HandleMethod is the name of the method subscribed to the bus for each handler type
+const HandleMethod = "Handle"
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// AutoSubscribe returns an fx option that resolves each of the given handler types from the fx container and
+// subscribes its Handle method to b. Types are given as sample values, e.g. (*UserHandler)(nil), and must be provided
+// to the container. The Handle method must have the signature of a bus handler. Subscription errors fail the fx
+// application start up. Handlers are named after their Handle method, e.g. github.com/org/users.(*UserHandler).Handle.
+//
+// fx does not resolve types from the container of an *fx.App once it is constructed, so the handlers are resolved by
+// the returned option when it is passed to fx.New
+//
+//	app := fx.New(
+//		fx.Provide(NewUserService, NewUserHandler),
+//		busfx.AutoSubscribe(msgBus, (*UserHandler)(nil)),
+//	)
+func AutoSubscribe(b bus.Bus, types ...interface{}) uberfx.Option {
+	in := make([]reflect.Type, len(types))
+	for i, t := range types {
+		if t == nil {
+			return uberfx.Error(fmt.Errorf("handler type at index %d must not be nil", i))
+		}
+		in[i] = reflect.TypeOf(t)
+		if _, ok := in[i].MethodByName(HandleMethod); !ok {
+			return uberfx.Error(fmt.Errorf("'%s' does not have a %s method", in[i], HandleMethod))
+		}
+	}
+
+	fnType := reflect.FuncOf(in, []reflect.Type{errorType}, false)
+	subscribe := reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+		for _, handler := range args {
+			name := bus.WithHandlerName(bus.MethodName(handler.Type(), HandleMethod))
+			if err := b.Subscribe(handler.MethodByName(HandleMethod).Interface(), name); err != nil {
+				return []reflect.Value{reflect.ValueOf(fmt.Errorf("failed to subscribe '%s': %w", handler.Type(), err))}
+			}
+		}
+		return []reflect.Value{reflect.Zero(errorType)}
+	})
+	return uberfx.Invoke(subscribe.Interface())
+}
@@ -0,0 +1,79 @@
+package fx_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	busfx "github.com/steinfletcher/bus/fx"
+	"github.com/stretchr/testify/assert"
+	uberfx "go.uber.org/fx"
+)
+
+type GetUserQuery struct {
+	ID     string
+	Result string
+}
+
+type UserService interface {
+	GetName(id string) string
+}
+
+type mockUserService struct{}
+
+func (mockUserService) GetName(id string) string {
+	return "Jan"
+}
+
+type UserHandler struct {
+	service UserService
+}
+
+func NewUserHandler(service UserService) *UserHandler {
+	return &UserHandler{service: service}
+}
+
+func (h *UserHandler) Handle(ctx context.Context, query *GetUserQuery) error {
+	query.Result = h.service.GetName(query.ID)
+	return nil
+}
+
+type NotAHandler struct{}
+
+func TestAutoSubscribe(t *testing.T) {
+	b := bus.New()
+	app := uberfx.New(
+		uberfx.NopLogger,
+		uberfx.Provide(func() UserService { return mockUserService{} }),
+		uberfx.Provide(NewUserHandler),
+		busfx.AutoSubscribe(b, (*UserHandler)(nil)),
+	)
+	assert.NoError(t, app.Err())
+
+	query := GetUserQuery{ID: "1234"}
+	err := b.Publish(context.Background(), &query)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Jan", query.Result)
+	assert.Equal(t, "github.com/steinfletcher/bus/fx_test.(*UserHandler).Handle", b.Subscriptions()[0].HandlerName)
+}
+
+func TestAutoSubscribe_MissingHandleMethod(t *testing.T) {
+	app := uberfx.New(
+		uberfx.NopLogger,
+		uberfx.Provide(func() *NotAHandler { return &NotAHandler{} }),
+		busfx.AutoSubscribe(bus.New(), (*NotAHandler)(nil)),
+	)
+
+	assert.Error(t, app.Err())
+	assert.Contains(t, app.Err().Error(), "'*fx_test.NotAHandler' does not have a Handle method")
+}
+
+func TestAutoSubscribe_HandlerNotProvided(t *testing.T) {
+	app := uberfx.New(
+		uberfx.NopLogger,
+		busfx.AutoSubscribe(bus.New(), (*UserHandler)(nil)),
+	)
+
+	assert.Error(t, app.Err())
+}
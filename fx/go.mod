@@ -0,0 +1,23 @@
+module github.com/steinfletcher/bus/fx
+
+go 1.22
+
+replace github.com/steinfletcher/bus => ../
+
+require (
+	github.com/steinfletcher/bus v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.7.0
+	go.uber.org/fx v1.17.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	go.uber.org/atomic v1.7.0 // indirect
+	go.uber.org/dig v1.14.1 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.22.0 // indirect
+	golang.org/x/sys v0.0.0-20220412211240-33da011f77ad // indirect
+	golang.org/x/time v0.5.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,223 @@
+// Package gateway publishes messages received over HTTP, gRPC and NATS to a bus. Messages are JSON encoded and
+// identified by the name of their Go type, e.g. a GetUserQuery struct is published as "GetUserQuery".
+//
+// Over HTTP messages are posted to /messages/{type}. Over gRPC they are sent to the Publish method of the
+// bus.gateway.Gateway service using the JSON codec, see Publish. Over NATS they are published to the subject
+// {NATSSubject}.{type}. Messages published to a type without a handler are rejected as not found.
+//
+// NATS is the only transport that connects to a remote server and so the only one with a connect timeout, see
+// GatewayOptions.NATSConnectTimeout. The HTTP and gRPC servers listen locally. Bridges to other brokers such as Redis
+// or Kafka are not provided.
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/steinfletcher/bus"
+	"google.golang.org/grpc"
+)
+
+// ErrUnknownMessageType is returned when a message is received for a type that is not registered with the gateway
+var ErrUnknownMessageType = errors.New("unknown message type")
+
+// ErrConnectionTimeout is returned when the connection to a remote server is not established within the connect timeout
+var ErrConnectionTimeout = errors.New("connection timed out")
+
+const (
+	// DefaultHTTPMaxMessageBytes is the largest message body accepted over HTTP if GatewayOptions.HTTPMaxMessageBytes
+	// is not set
+	DefaultHTTPMaxMessageBytes = 1 << 20
+	// DefaultHTTPReadTimeout is the time allowed to read an HTTP request if GatewayOptions.HTTPReadTimeout is not set
+	DefaultHTTPReadTimeout = 10 * time.Second
+)
+
+// GatewayOptions configures the protocols served by the gateway. A protocol is disabled if its address is empty
+type GatewayOptions struct {
+	// HTTPAddr is the address the HTTP server listens on, e.g. ":8080"
+	HTTPAddr string
+	// GRPCAddr is the address the gRPC server listens on, e.g. ":9090"
+	GRPCAddr string
+	// NATSURL is the URL of the NATS server to subscribe to, e.g. nats.DefaultURL
+	NATSURL string
+	// NATSSubject is the subject prefix messages are published to over NATS. Defaults to "bus"
+	NATSSubject string
+	// NATSConnectTimeout is the time allowed to establish the connection to the NATS server before ErrConnectionTimeout
+	// is returned. Defaults to nats.DefaultTimeout
+	NATSConnectTimeout time.Duration
+	// HTTPMaxMessageBytes is the largest message body accepted over HTTP. Larger messages are rejected with status 413.
+	// Defaults to DefaultHTTPMaxMessageBytes
+	HTTPMaxMessageBytes int64
+	// HTTPReadTimeout is the time allowed to read an HTTP request, including its headers and body. Defaults to
+	// DefaultHTTPReadTimeout
+	HTTPReadTimeout time.Duration
+	// Types are the message types accepted by the gateway, given as sample values, e.g. &GetUserQuery{}
+	Types []interface{}
+}
+
+// Gateway receives messages over the enabled protocols and publishes them to a bus
+type Gateway struct {
+	bus   bus.Bus
+	types map[string]reflect.Type
+
+	httpListener net.Listener
+	httpServer   *http.Server
+	grpcListener net.Listener
+	grpcServer   *grpc.Server
+	natsConn     *nats.Conn
+
+	closeOnce sync.Once
+}
+
+// MultiProtocolGateway starts a listener for each protocol enabled in opts and returns once they are listening. Call
+// Close to stop the gateway.
+func MultiProtocolGateway(b bus.Bus, opts GatewayOptions) (*Gateway, error) {
+	g := &Gateway{bus: b, types: map[string]reflect.Type{}}
+	for _, msgType := range opts.Types {
+		typ := reflect.TypeOf(msgType)
+		name := typeName(typ)
+		if existing, ok := g.types[name]; ok {
+			return nil, fmt.Errorf("message types %s and %s have the same name", existing, typ)
+		}
+		g.types[name] = typ
+	}
+
+	if err := g.start(opts); err != nil {
+		_ = g.Close()
+		return nil, err
+	}
+	return g, nil
+}
+
+func (g *Gateway) start(opts GatewayOptions) error {
+	var err error
+	if opts.HTTPAddr != "" {
+		if g.httpListener, err = net.Listen("tcp", opts.HTTPAddr); err != nil {
+			return fmt.Errorf("failed to listen for HTTP: %w", err)
+		}
+		maxBytes := opts.HTTPMaxMessageBytes
+		if maxBytes <= 0 {
+			maxBytes = DefaultHTTPMaxMessageBytes
+		}
+		readTimeout := opts.HTTPReadTimeout
+		if readTimeout <= 0 {
+			readTimeout = DefaultHTTPReadTimeout
+		}
+		g.httpServer = &http.Server{
+			Handler:           g.httpHandler(maxBytes),
+			ReadHeaderTimeout: readTimeout,
+			ReadTimeout:       readTimeout,
+		}
+		go func() {
+			_ = g.httpServer.Serve(g.httpListener)
+		}()
+	}
+	if opts.GRPCAddr != "" {
+		if g.grpcListener, err = net.Listen("tcp", opts.GRPCAddr); err != nil {
+			return fmt.Errorf("failed to listen for gRPC: %w", err)
+		}
+		g.grpcServer = grpc.NewServer(grpc.ForceServerCodec(JSONCodec{}))
+		g.grpcServer.RegisterService(&serviceDesc, g)
+		go func() {
+			_ = g.grpcServer.Serve(g.grpcListener)
+		}()
+	}
+	if opts.NATSURL != "" {
+		subject := opts.NATSSubject
+		if subject == "" {
+			subject = "bus"
+		}
+		timeout := opts.NATSConnectTimeout
+		if timeout == 0 {
+			timeout = nats.DefaultTimeout
+		}
+		if g.natsConn, err = nats.Connect(opts.NATSURL, nats.Timeout(timeout)); err != nil {
+			if isTimeout(err) {
+				return fmt.Errorf("failed to connect to NATS at %s within %s: %w", opts.NATSURL, timeout, ErrConnectionTimeout)
+			}
+			return fmt.Errorf("failed to connect to NATS: %w", err)
+		}
+		if _, err = g.natsConn.Subscribe(subject+".*", g.natsHandler(subject)); err != nil {
+			return fmt.Errorf("failed to subscribe to NATS: %w", err)
+		}
+	}
+	return nil
+}
+
+// isTimeout reports whether err was caused by a connection or handshake exceeding its deadline
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, nats.ErrTimeout) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// HTTPAddr returns the address of the HTTP listener, or an empty string if HTTP is disabled
+func (g *Gateway) HTTPAddr() string {
+	if g.httpListener == nil {
+		return ""
+	}
+	return g.httpListener.Addr().String()
+}
+
+// GRPCAddr returns the address of the gRPC listener, or an empty string if gRPC is disabled
+func (g *Gateway) GRPCAddr() string {
+	if g.grpcListener == nil {
+		return ""
+	}
+	return g.grpcListener.Addr().String()
+}
+
+// Close stops the listeners. Messages that are being published are not waited for
+func (g *Gateway) Close() error {
+	var err error
+	g.closeOnce.Do(func() {
+		if g.httpServer != nil {
+			err = g.httpServer.Close()
+		} else if g.httpListener != nil {
+			err = g.httpListener.Close()
+		}
+		if g.grpcServer != nil {
+			g.grpcServer.Stop()
+		} else if g.grpcListener != nil {
+			_ = g.grpcListener.Close()
+		}
+		if g.natsConn != nil {
+			g.natsConn.Close()
+		}
+	})
+	return err
+}
+
+// decode returns the message of the named type encoded in data
+func (g *Gateway) decode(name string, data []byte) (bus.Message, error) {
+	typ, ok := g.types[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownMessageType, name)
+	}
+	elem := typ
+	if typ.Kind() == reflect.Ptr {
+		elem = typ.Elem()
+	}
+	msg := reflect.New(elem)
+	if err := json.Unmarshal(data, msg.Interface()); err != nil {
+		return nil, fmt.Errorf("invalid message '%s': %w", name, err)
+	}
+	if typ.Kind() == reflect.Ptr {
+		return msg.Interface(), nil
+	}
+	return msg.Elem().Interface(), nil
+}
+
+// typeName returns the name a message type is registered under
+func typeName(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Name()
+}
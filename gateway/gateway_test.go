@@ -0,0 +1,183 @@
+package gateway_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/gateway"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+type CreateUserCommand struct {
+	Name string `json:"name"`
+}
+
+func newGateway(t *testing.T, handler func(ctx context.Context, cmd *CreateUserCommand) error) *gateway.Gateway {
+	b := bus.New()
+	assert.NoError(t, b.Subscribe(handler))
+	g, err := gateway.MultiProtocolGateway(b, gateway.GatewayOptions{
+		HTTPAddr: "127.0.0.1:0",
+		GRPCAddr: "127.0.0.1:0",
+		Types:    []interface{}{&CreateUserCommand{}},
+	})
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, g.Close())
+	})
+	return g
+}
+
+func dial(t *testing.T, g *gateway.Gateway) *grpc.ClientConn {
+	conn, err := grpc.NewClient(g.GRPCAddr(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(gateway.JSONCodec{})),
+	)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+func TestMultiProtocolGateway(t *testing.T) {
+	var mu sync.Mutex
+	var names []string
+	g := newGateway(t, func(ctx context.Context, cmd *CreateUserCommand) error {
+		mu.Lock()
+		defer mu.Unlock()
+		names = append(names, cmd.Name)
+		return nil
+	})
+
+	res, err := http.Post("http://"+g.HTTPAddr()+"/messages/CreateUserCommand", "application/json", strings.NewReader(`{"name":"jan"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	_ = res.Body.Close()
+
+	err = gateway.Publish(context.Background(), dial(t, g), "CreateUserCommand", CreateUserCommand{Name: "sam"})
+	assert.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"jan", "sam"}, names)
+}
+
+func TestMultiProtocolGateway_HTTPErrors(t *testing.T) {
+	g := newGateway(t, func(ctx context.Context, cmd *CreateUserCommand) error {
+		return errors.New("user exists")
+	})
+	tests := map[string]struct {
+		path   string
+		body   string
+		status int
+	}{
+		"unknown type":    {path: "/messages/DeleteUserCommand", body: `{}`, status: http.StatusNotFound},
+		"invalid message": {path: "/messages/CreateUserCommand", body: `{`, status: http.StatusBadRequest},
+		"handler error":   {path: "/messages/CreateUserCommand", body: `{}`, status: http.StatusInternalServerError},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			res, err := http.Post("http://"+g.HTTPAddr()+test.path, "application/json", strings.NewReader(test.body))
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.status, res.StatusCode)
+			_ = res.Body.Close()
+		})
+	}
+}
+
+func TestMultiProtocolGateway_NoHandler(t *testing.T) {
+	g, err := gateway.MultiProtocolGateway(bus.New(), gateway.GatewayOptions{
+		HTTPAddr: "127.0.0.1:0",
+		GRPCAddr: "127.0.0.1:0",
+		Types:    []interface{}{&CreateUserCommand{}},
+	})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	res, err := http.Post("http://"+g.HTTPAddr()+"/messages/CreateUserCommand", "application/json", strings.NewReader(`{}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	_ = res.Body.Close()
+
+	err = gateway.Publish(context.Background(), dial(t, g), "CreateUserCommand", CreateUserCommand{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestMultiProtocolGateway_HTTPMaxMessageBytes(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, cmd *CreateUserCommand) error { return nil })
+	g, err := gateway.MultiProtocolGateway(b, gateway.GatewayOptions{
+		HTTPAddr:            "127.0.0.1:0",
+		HTTPMaxMessageBytes: 16,
+		Types:               []interface{}{&CreateUserCommand{}},
+	})
+	assert.NoError(t, err)
+	defer g.Close()
+
+	res, err := http.Post("http://"+g.HTTPAddr()+"/messages/CreateUserCommand", "application/json", strings.NewReader(`{"name":"jan"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	_ = res.Body.Close()
+
+	res, err = http.Post("http://"+g.HTTPAddr()+"/messages/CreateUserCommand", "application/json", strings.NewReader(`{"name":"janina"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.StatusCode)
+	_ = res.Body.Close()
+}
+
+func TestMultiProtocolGateway_GRPCErrors(t *testing.T) {
+	g := newGateway(t, func(ctx context.Context, cmd *CreateUserCommand) error {
+		return errors.New("user exists")
+	})
+	conn := dial(t, g)
+
+	err := gateway.Publish(context.Background(), conn, "DeleteUserCommand", struct{}{})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	err = gateway.Publish(context.Background(), conn, "CreateUserCommand", CreateUserCommand{})
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	assert.Equal(t, "user exists", status.Convert(err).Message())
+}
+
+func TestMultiProtocolGateway_ListenError(t *testing.T) {
+	_, err := gateway.MultiProtocolGateway(bus.New(), gateway.GatewayOptions{HTTPAddr: "invalid"})
+
+	assert.Error(t, err)
+}
+
+func TestMultiProtocolGateway_NATSConnectTimeout(t *testing.T) {
+	// accepts connections but never sends the NATS INFO handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	start := time.Now()
+	_, err = gateway.MultiProtocolGateway(bus.New(), gateway.GatewayOptions{
+		NATSURL:            "nats://" + listener.Addr().String(),
+		NATSConnectTimeout: 50 * time.Millisecond,
+	})
+
+	assert.True(t, errors.Is(err, gateway.ErrConnectionTimeout), "unexpected error: %v", err)
+	assert.Less(t, time.Since(start), time.Second)
+}
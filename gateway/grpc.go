@@ -0,0 +1,95 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/steinfletcher/bus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PublishMethod is the full name of the gRPC method that publishes a message
+const PublishMethod = "/bus.gateway.Gateway/Publish"
+
+// PublishRequest is the request of the gRPC Publish method
+type PublishRequest struct {
+	// Type is the name of the message type
+	Type string `json:"type"`
+	// Message is the JSON encoded message
+	Message json.RawMessage `json:"message"`
+}
+
+// PublishResponse is the response of the gRPC Publish method
+type PublishResponse struct{}
+
+// JSONCodec encodes gRPC messages as JSON. The gateway does not use protocol buffers, so clients must call it with
+// this codec, e.g. grpc.WithDefaultCallOptions(grpc.ForceCodec(gateway.JSONCodec{}))
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (JSONCodec) Name() string {
+	return "json"
+}
+
+// Publish publishes msg through the gateway listening on the other end of conn. conn must use JSONCodec
+func Publish(ctx context.Context, conn grpc.ClientConnInterface, msgType string, msg interface{}) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.Invoke(ctx, PublishMethod, &PublishRequest{Type: msgType, Message: data}, &PublishResponse{})
+}
+
+type gatewayServer interface {
+	publishGRPC(ctx context.Context, req *PublishRequest) (*PublishResponse, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: "bus.gateway.Gateway",
+	HandlerType: (*gatewayServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Publish",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := &PublishRequest{}
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			server := srv.(gatewayServer)
+			if interceptor == nil {
+				return server.publishGRPC(ctx, req)
+			}
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: PublishMethod}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return server.publishGRPC(ctx, req.(*PublishRequest))
+			})
+		},
+	}},
+}
+
+func (g *Gateway) publishGRPC(ctx context.Context, req *PublishRequest) (*PublishResponse, error) {
+	msg, err := g.decode(req.Type, req.Message)
+	if errors.Is(err, ErrUnknownMessageType) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	err = g.bus.Publish(ctx, msg)
+	if errors.Is(err, bus.ErrHandlerNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Unknown, err.Error())
+	}
+	return &PublishResponse{}, nil
+}
@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/steinfletcher/bus"
+)
+
+// httpHandler returns the handler of the HTTP server, which rejects message bodies larger than maxBytes
+func (g *Gateway) httpHandler(maxBytes int64) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /messages/{type}", func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		msg, err := g.decode(r.PathValue("type"), data)
+		if errors.Is(err, ErrUnknownMessageType) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		err = g.bus.Publish(r.Context(), msg)
+		if errors.Is(err, bus.ErrHandlerNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
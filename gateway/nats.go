@@ -0,0 +1,30 @@
+package gateway
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsHandler publishes the messages received on subject.{type}. If the sender expects a reply it receives an empty
+// message on success and the error message otherwise
+func (g *Gateway) natsHandler(subject string) nats.MsgHandler {
+	return func(m *nats.Msg) {
+		err := func() error {
+			msg, err := g.decode(strings.TrimPrefix(m.Subject, subject+"."), m.Data)
+			if err != nil {
+				return err
+			}
+			return g.bus.Publish(context.Background(), msg)
+		}()
+		if m.Reply == "" {
+			return
+		}
+		var reply []byte
+		if err != nil {
+			reply = []byte(err.Error())
+		}
+		_ = m.Respond(reply)
+	}
+}
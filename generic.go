@@ -0,0 +1,29 @@
+package bus
+
+import "context"
+
+// SubscribeTo subscribes fn to messages of type *T. It is a type safe alternative to Subscriber.Subscribe, the
+// handler signature is checked by the compiler rather than when subscribing
+//
+//	err := bus.SubscribeTo(msgBus, func(ctx context.Context, query *GetUserQuery) error {
+//	   return nil
+//	})
+//
+// T must be a struct type, otherwise an error wrapping ErrNonStructMessage is returned. opts configure the
+// subscription, e.g. WithHandlerName
+func SubscribeTo[T any](b Bus, fn func(ctx context.Context, msg *T) error, opts ...SubscribeOption) error {
+	return b.Subscribe(fn, opts...)
+}
+
+// SubscribeAsyncTo subscribes fn asynchronously to messages of type *T. It is a type safe alternative to
+// Subscriber.SubscribeAsync. Errors returned by fn are handled as the errors of any async handler, so they can be
+// retried, see WithRetry, dead lettered and logged. opts configure the subscription, e.g. WithWorkers
+func SubscribeAsyncTo[T any](b Bus, fn func(ctx context.Context, msg *T) error, opts ...SubscribeOption) error {
+	return b.SubscribeAsync(fn, opts...)
+}
+
+// PublishTo publishes msg to the handlers of *T. It is a type safe alternative to Publisher.Publish which only
+// accepts messages that handlers subscribed with SubscribeTo can receive
+func PublishTo[T any](ctx context.Context, b Bus, msg *T) error {
+	return b.Publish(ctx, msg)
+}
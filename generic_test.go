@@ -0,0 +1,71 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeTo(t *testing.T) {
+	b := bus.New()
+	err := bus.SubscribeTo(b, func(ctx context.Context, query *GetUserQuery) error {
+		query.Result = UserResult{Name: "Jan"}
+		return nil
+	})
+	assert.NoError(t, err)
+
+	query := &GetUserQuery{ID: "1234"}
+	assert.NoError(t, bus.PublishTo(context.Background(), b, query))
+
+	assert.Equal(t, UserResult{Name: "Jan"}, query.Result)
+}
+
+func TestSubscribeAsyncTo(t *testing.T) {
+	b := bus.New()
+	var wg sync.WaitGroup
+	wg.Add(1)
+	var received *GetUserQuery
+	err := bus.SubscribeAsyncTo(b, func(ctx context.Context, query *GetUserQuery) error {
+		received = query
+		wg.Done()
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, bus.PublishTo(context.Background(), b, &GetUserQuery{ID: "1234"}))
+	wg.Wait()
+
+	assert.Equal(t, &GetUserQuery{ID: "1234"}, received)
+}
+
+func TestSubscribeTo_NonStruct(t *testing.T) {
+	err := bus.SubscribeTo(bus.New(), func(ctx context.Context, id *string) error {
+		return nil
+	})
+
+	assert.ErrorIs(t, err, bus.ErrNonStructMessage)
+}
+
+func TestSubscribeAsyncTo_Error(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDeadLetterQueue(10))
+	done := make(chan struct{})
+	err := bus.SubscribeAsyncTo(b, func(ctx context.Context, query *GetUserQuery) error {
+		defer close(done)
+		return errors.New("user not found")
+	}, bus.WithHandlerName("getUser"), bus.WithQueueSize(1))
+	assert.NoError(t, err)
+
+	assert.NoError(t, bus.PublishTo(context.Background(), b, &GetUserQuery{ID: "1234"}))
+	<-done
+	assert.NoError(t, b.Close(context.Background()))
+
+	assert.Equal(t, "getUser", b.Subscriptions()[0].HandlerName)
+	deadLetters := bus.DrainDeadLetters(b)
+	if assert.Len(t, deadLetters, 1) {
+		assert.EqualError(t, deadLetters[0].Reason, "user not found")
+	}
+}
@@ -0,0 +1,52 @@
+package bus
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// healthyQueueFillRatio is the fill ratio of an async handler queue above which the bus is reported as degraded
+const healthyQueueFillRatio = 0.9
+
+// IsHealthy reports whether b is healthy. The bus is unhealthy when it is closed and degraded when the queue of an
+// async handler is at least 90% full or the execution budget of a message type has been used, see
+// WithCumulativeBudget. reasons describes each problem and is empty when the bus is healthy. A warning is logged when
+// the bus becomes unhealthy and a message when it recovers.
+func IsHealthy(b Bus) (healthy bool, reasons []string) {
+	e, ok := b.(*eventBus)
+	if !ok {
+		return true, nil
+	}
+	if e.closed.Load() {
+		reasons = append(reasons, "bus is closed")
+	}
+	for _, key := range e.handlers.Keys() {
+		handlers, _ := e.handlers.Get(key)
+		for _, handler := range handlers {
+			if handler.queue == nil || handler.queue.Cap() == 0 {
+				continue
+			}
+			if ratio := handler.queue.FillRatio(); ratio >= healthyQueueFillRatio {
+				reasons = append(reasons, fmt.Sprintf("async queue of handler %s for %s is %.0f%% full", handler.name, key, ratio*100))
+			}
+		}
+	}
+	var budgetReasons []string
+	for msgTypeName, budget := range e.budgets {
+		if budget.Exceeded() {
+			budgetReasons = append(budgetReasons, fmt.Sprintf("execution budget for %s is exceeded", msgTypeName))
+		}
+	}
+	sort.Strings(budgetReasons)
+	reasons = append(reasons, budgetReasons...)
+	healthy = len(reasons) == 0
+	if e.degraded.CompareAndSwap(healthy, !healthy) {
+		if healthy {
+			e.logger.Infof("bus is healthy")
+		} else {
+			e.logger.Warnf("bus is degraded: %s", strings.Join(reasons, ", "))
+		}
+	}
+	return healthy, reasons
+}
@@ -0,0 +1,80 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsHealthy(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(getUserHandler)
+
+	healthy, reasons := bus.IsHealthy(b)
+
+	assert.True(t, healthy)
+	assert.Empty(t, reasons)
+}
+
+func TestIsHealthy_QueueFull(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDefaultQueueSize(2))
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{}, 1)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		started <- struct{}{}
+		<-release
+	})
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+	<-started
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+
+	healthy, reasons := bus.IsHealthy(b)
+
+	assert.False(t, healthy)
+	assert.Equal(t, []string{"async queue of handler github.com/steinfletcher/bus_test.TestIsHealthy_QueueFull.func1 for *bus_test.GetUserQuery is 100% full"}, reasons)
+}
+
+func TestIsHealthy_BudgetExceeded(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithCumulativeBudget(&GetUserQuery{}, time.Millisecond, time.Hour))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	})
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+
+	healthy, reasons := bus.IsHealthy(b)
+
+	assert.False(t, healthy)
+	assert.Equal(t, []string{"execution budget for *bus_test.GetUserQuery is exceeded"}, reasons)
+}
+
+func TestIsHealthy_LogsChanges(t *testing.T) {
+	logger := &testLogger{}
+	b := bus.NewWithOptions(bus.WithLogger(logger), bus.WithCumulativeBudget(&GetUserQuery{}, time.Millisecond, time.Hour))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	})
+
+	bus.IsHealthy(b)
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+	bus.IsHealthy(b)
+	bus.IsHealthy(b)
+
+	assert.Equal(t, []string{"WARN bus is degraded: execution budget for *bus_test.GetUserQuery is exceeded"}, logger.Messages())
+}
+
+func TestIsHealthy_Closed(t *testing.T) {
+	b := bus.New()
+	_ = b.Close(context.Background())
+
+	healthy, reasons := bus.IsHealthy(b)
+
+	assert.False(t, healthy)
+	assert.Equal(t, []string{"bus is closed"}, reasons)
+}
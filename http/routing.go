@@ -0,0 +1,84 @@
+// Package http publishes HTTP requests to a bus. Each route maps a request to a message which is published to the
+// bus, the message is then written to the response as JSON so that queries can return the results set by handlers.
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/steinfletcher/bus"
+)
+
+// ErrRouteNotFound is returned when no route is registered for the method and path of a request
+var ErrRouteNotFound = errors.New("route not found")
+
+type route struct {
+	method string
+	path   string
+}
+
+// RoutingBus is a bus that also serves HTTP requests by publishing the message registered for the request route
+type RoutingBus struct {
+	bus.Bus
+	mu     sync.RWMutex
+	routes map[route]func(*http.Request) (bus.Message, error)
+}
+
+// NewRoutingBus creates a RoutingBus that publishes to b
+func NewRoutingBus(b bus.Bus) *RoutingBus {
+	return &RoutingBus{Bus: b, routes: map[route]func(*http.Request) (bus.Message, error){}}
+}
+
+// RegisterRoute routes requests with the given method and path to msgFactory, which creates the message published for
+// the request. Paths are matched exactly, path parameters can be passed in the query string. Registering a route
+// again replaces its factory.
+func (b *RoutingBus) RegisterRoute(method, path string, msgFactory func(*http.Request) (bus.Message, error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.routes[route{method: method, path: path}] = msgFactory
+}
+
+// RequestMessage returns the message for r created by the factory of its route. An error wrapping ErrRouteNotFound is
+// returned if no route matches r.
+func (b *RoutingBus) RequestMessage(r *http.Request) (bus.Message, error) {
+	b.mu.RLock()
+	msgFactory, ok := b.routes[route{method: r.Method, path: r.URL.Path}]
+	b.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrRouteNotFound, r.Method, r.URL.Path)
+	}
+	return msgFactory(r)
+}
+
+// ServeHTTP publishes the message for r and responds with the message encoded as JSON. It responds with 404 Not Found
+// if no route matches r, 400 Bad Request if the message factory fails and 500 Internal Server Error if publishing
+// fails, with the error in the body as {"error": "..."}.
+func (b *RoutingBus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	msg, err := b.RequestMessage(r)
+	if errors.Is(err, ErrRouteNotFound) {
+		writeJSON(w, http.StatusNotFound, errorDTO{Message: err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorDTO{Message: err.Error()})
+		return
+	}
+	if err := b.Publish(r.Context(), msg); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorDTO{Message: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, msg)
+}
+
+type errorDTO struct {
+	Message string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,84 @@
+package http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	bushttp "github.com/steinfletcher/bus/http"
+	"github.com/stretchr/testify/assert"
+)
+
+type GetUserQuery struct {
+	ID     string `json:"id"`
+	Result string `json:"result"`
+}
+
+func newRoutingBus() *bushttp.RoutingBus {
+	b := bushttp.NewRoutingBus(bus.New())
+	b.RegisterRoute(http.MethodGet, "/users", func(r *http.Request) (bus.Message, error) {
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			return nil, errors.New("id is required")
+		}
+		return &GetUserQuery{ID: id}, nil
+	})
+	return b
+}
+
+func TestRoutingBus_ServeHTTP(t *testing.T) {
+	b := newRoutingBus()
+	var published *GetUserQuery
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		published = query
+		query.Result = "Jan"
+		return nil
+	})
+	recorder := httptest.NewRecorder()
+
+	b.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/users?id=1234", nil))
+
+	assert.Equal(t, &GetUserQuery{ID: "1234", Result: "Jan"}, published)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"id":"1234","result":"Jan"}`, recorder.Body.String())
+}
+
+func TestRoutingBus_ServeHTTP_Errors(t *testing.T) {
+	b := newRoutingBus()
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return errors.New("user not found")
+	})
+	tests := map[string]struct {
+		method string
+		target string
+		status int
+		body   string
+	}{
+		"route not found":  {method: http.MethodPost, target: "/users", status: http.StatusNotFound, body: `{"error":"route not found: POST /users"}`},
+		"factory error":    {method: http.MethodGet, target: "/users", status: http.StatusBadRequest, body: `{"error":"id is required"}`},
+		"handler error":    {method: http.MethodGet, target: "/users?id=1234", status: http.StatusInternalServerError, body: `{"error":"user not found"}`},
+		"path not matched": {method: http.MethodGet, target: "/users/1234", status: http.StatusNotFound, body: `{"error":"route not found: GET /users/1234"}`},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			b.ServeHTTP(recorder, httptest.NewRequest(test.method, test.target, nil))
+
+			assert.Equal(t, test.status, recorder.Code)
+			assert.JSONEq(t, test.body, recorder.Body.String())
+		})
+	}
+}
+
+func TestRoutingBus_RequestMessage(t *testing.T) {
+	b := newRoutingBus()
+
+	msg, err := b.RequestMessage(httptest.NewRequest(http.MethodGet, "/users?id=1234", nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &GetUserQuery{ID: "1234"}, msg)
+}
@@ -0,0 +1,67 @@
+package bus
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// interfaceTypes holds the interface types that handlers are subscribed to, ordered by name
+type interfaceTypes struct {
+	sync.RWMutex
+	types []reflect.Type
+}
+
+// Add adds t unless it is already held
+func (i *interfaceTypes) Add(t reflect.Type) {
+	i.Lock()
+	defer i.Unlock()
+	n := sort.Search(len(i.types), func(j int) bool { return i.types[j].String() >= t.String() })
+	if n < len(i.types) && i.types[n] == t {
+		return
+	}
+	types := make([]reflect.Type, 0, len(i.types)+1)
+	types = append(types, i.types[:n]...)
+	types = append(types, t)
+	i.types = append(types, i.types[n:]...)
+}
+
+// Implemented returns the held interface types that t implements
+func (i *interfaceTypes) Implemented(t reflect.Type) []reflect.Type {
+	i.RLock()
+	defer i.RUnlock()
+	var implemented []reflect.Type
+	for _, iface := range i.types {
+		if t.Implements(iface) {
+			implemented = append(implemented, iface)
+		}
+	}
+	return implemented
+}
+
+// withInterfaceHandlers returns handlers followed by the handlers subscribed to interface types implemented by
+// msgType, ordered by priority. The handlers of each interface type follow the handlers of the message type when
+// their priorities are equal
+func (e *eventBus) withInterfaceHandlers(handlers []handler, msgType reflect.Type) []handler {
+	ifaces := e.interfaces.Implemented(msgType)
+	if len(ifaces) == 0 {
+		return handlers
+	}
+	merged := handlers
+	for _, iface := range ifaces {
+		if matched, ok := e.handlers.Get(iface.String()); ok {
+			merged = append(merged[:len(merged):len(merged)], matched...)
+		}
+	}
+	if len(merged) > len(handlers) {
+		sort.SliceStable(merged, func(i, j int) bool {
+			return merged[i].priority > merged[j].priority
+		})
+	}
+	return merged
+}
+
+func (e *eventBus) SubscribeAll(fn func(ctx context.Context, msg Message) error, opts ...SubscribeOption) error {
+	return e.subscribe(fn, false, opts)
+}
@@ -0,0 +1,107 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type DomainEvent interface {
+	AggregateID() string
+}
+
+type OrderCreated struct {
+	ID string
+}
+
+func (e *OrderCreated) AggregateID() string {
+	return e.ID
+}
+
+type OrderShipped struct {
+	ID string
+}
+
+func (e OrderShipped) AggregateID() string {
+	return e.ID
+}
+
+func TestBus_SubscribeInterface(t *testing.T) {
+	b := bus.New()
+	var audited []string
+	var created []string
+	err := b.Subscribe(func(ctx context.Context, event DomainEvent) error {
+		audited = append(audited, event.AggregateID())
+		return nil
+	})
+	assert.NoError(t, err)
+	_ = b.Subscribe(func(ctx context.Context, event *OrderCreated) error {
+		created = append(created, event.ID)
+		return nil
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &OrderCreated{ID: "1"}))
+	assert.NoError(t, b.Publish(context.Background(), OrderShipped{ID: "2"}))
+	assert.NoError(t, b.Publish(context.Background(), &OrderShipped{ID: "3"}))
+	err = b.Publish(context.Background(), OrderCreated{ID: "4"})
+
+	assert.ErrorIs(t, err, bus.ErrHandlerNotFound)
+	assert.Equal(t, []string{"1", "2", "3"}, audited)
+	assert.Equal(t, []string{"1"}, created)
+}
+
+func TestBus_SubscribeInterface_Priority(t *testing.T) {
+	b := bus.New()
+	var calls []string
+	_ = b.Subscribe(func(ctx context.Context, event *OrderCreated) error {
+		calls = append(calls, "created")
+		return nil
+	})
+	_ = b.Subscribe(func(ctx context.Context, event DomainEvent) error {
+		calls = append(calls, "validate")
+		return nil
+	}, bus.WithPriority(1))
+
+	assert.NoError(t, b.Publish(context.Background(), &OrderCreated{ID: "1"}))
+
+	assert.Equal(t, []string{"validate", "created"}, calls)
+}
+
+func TestBus_SubscribeAsyncInterface(t *testing.T) {
+	b := bus.New()
+	received := make(chan DomainEvent, 1)
+	_ = b.SubscribeAsync(func(ctx context.Context, event DomainEvent) {
+		received <- event
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &OrderCreated{ID: "1"}))
+
+	assert.Equal(t, &OrderCreated{ID: "1"}, <-received)
+}
+
+func TestBus_SubscribeAll(t *testing.T) {
+	b := bus.New()
+	var received []bus.Message
+	err := b.SubscribeAll(func(ctx context.Context, msg bus.Message) error {
+		received = append(received, msg)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Publish(context.Background(), &OrderCreated{ID: "1"}))
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	assert.Equal(t, []bus.Message{&OrderCreated{ID: "1"}, &GetUserQuery{ID: "1234"}}, received)
+}
+
+func TestBus_SubscribeAll_Unsubscribe(t *testing.T) {
+	b := bus.New()
+	handler := func(ctx context.Context, msg bus.Message) error { return nil }
+	_ = b.SubscribeAll(handler)
+
+	assert.NoError(t, b.Unsubscribe(handler))
+
+	assert.ErrorIs(t, b.Publish(context.Background(), &OrderCreated{ID: "1"}), bus.ErrHandlerNotFound)
+}
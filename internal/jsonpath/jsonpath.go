@@ -0,0 +1,303 @@
+// Package jsonpath evaluates a subset of JSONPath expressions against decoded JSON values.
+//
+// The supported syntax is the root $, child access by name .name or ['name'], wildcards .* and [*], array indexes
+// [n] and filters [?(@.path)] and [?(@.path op literal)] where op is one of ==, !=, <, <=, >, >= and the literal is a
+// number, quoted string, true, false or null. A filter applied to an array selects the matching elements. A filter
+// applied to an object selects the object itself if it matches.
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a compiled JSONPath expression
+type Path struct {
+	expr  string
+	steps []step
+}
+
+type step func(node interface{}) []interface{}
+
+// Compile parses a JSONPath expression
+func Compile(expr string) (*Path, error) {
+	p := &parser{expr: expr}
+	steps, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath '%s': %w", expr, err)
+	}
+	return &Path{expr: expr, steps: steps}, nil
+}
+
+// Evaluate returns the values selected by the path from a value decoded by encoding/json
+func (p *Path) Evaluate(root interface{}) []interface{} {
+	nodes := []interface{}{root}
+	for _, step := range p.steps {
+		var next []interface{}
+		for _, node := range nodes {
+			next = append(next, step(node)...)
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (p *Path) String() string {
+	return p.expr
+}
+
+type parser struct {
+	expr string
+	pos  int
+}
+
+func (p *parser) parse() ([]step, error) {
+	if !strings.HasPrefix(p.expr, "$") {
+		return nil, fmt.Errorf("must start with $")
+	}
+	p.pos = 1
+	var steps []step
+	for p.pos < len(p.expr) {
+		var s step
+		var err error
+		switch p.expr[p.pos] {
+		case '.':
+			p.pos++
+			s, err = p.parseDotChild()
+		case '[':
+			p.pos++
+			s, err = p.parseBracket()
+		default:
+			err = fmt.Errorf("unexpected '%c' at position %d", p.expr[p.pos], p.pos)
+		}
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
+func (p *parser) parseDotChild() (step, error) {
+	if p.pos < len(p.expr) && p.expr[p.pos] == '*' {
+		p.pos++
+		return wildcard, nil
+	}
+	name := p.readName()
+	if name == "" {
+		return nil, fmt.Errorf("expected name at position %d", p.pos)
+	}
+	return child(name), nil
+}
+
+func (p *parser) readName() string {
+	start := p.pos
+	for p.pos < len(p.expr) && p.expr[p.pos] != '.' && p.expr[p.pos] != '[' {
+		p.pos++
+	}
+	return p.expr[start:p.pos]
+}
+
+func (p *parser) parseBracket() (step, error) {
+	end := strings.LastIndex(p.expr[p.pos:], "]")
+	if p.pos < len(p.expr) && p.expr[p.pos] == '?' {
+		end = p.matchingBracket()
+	} else if end >= 0 {
+		end = p.pos + strings.Index(p.expr[p.pos:], "]")
+	}
+	if end < 0 {
+		return nil, fmt.Errorf("missing ] for [ at position %d", p.pos-1)
+	}
+	content := strings.TrimSpace(p.expr[p.pos:end])
+	p.pos = end + 1
+
+	switch {
+	case content == "*":
+		return wildcard, nil
+	case strings.HasPrefix(content, "?(") && strings.HasSuffix(content, ")"):
+		return parseFilter(strings.TrimSpace(content[2 : len(content)-1]))
+	case isQuoted(content):
+		return child(content[1 : len(content)-1]), nil
+	default:
+		i, err := strconv.Atoi(content)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index '%s'", content)
+		}
+		return index(i), nil
+	}
+}
+
+// matchingBracket returns the position of the ] that closes a filter, ignoring brackets in quoted strings
+func (p *parser) matchingBracket() int {
+	depth := 1
+	var quote byte
+	for i := p.pos; i < len(p.expr); i++ {
+		c := p.expr[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0]
+}
+
+func child(name string) step {
+	return func(node interface{}) []interface{} {
+		if obj, ok := node.(map[string]interface{}); ok {
+			if value, ok := obj[name]; ok {
+				return []interface{}{value}
+			}
+		}
+		return nil
+	}
+}
+
+func index(i int) step {
+	return func(node interface{}) []interface{} {
+		arr, ok := node.([]interface{})
+		if !ok {
+			return nil
+		}
+		if i < 0 {
+			i += len(arr)
+		}
+		if i < 0 || i >= len(arr) {
+			return nil
+		}
+		return []interface{}{arr[i]}
+	}
+}
+
+func wildcard(node interface{}) []interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		values := make([]interface{}, 0, len(n))
+		for _, value := range n {
+			values = append(values, value)
+		}
+		return values
+	case []interface{}:
+		return n
+	}
+	return nil
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func parseFilter(expr string) (step, error) {
+	if !strings.HasPrefix(expr, "@") {
+		return nil, fmt.Errorf("filter '%s' must start with @", expr)
+	}
+	lhs, op, rhs := expr, "", ""
+	for _, candidate := range operators {
+		if i := strings.Index(expr, candidate); i >= 0 {
+			lhs, op, rhs = strings.TrimSpace(expr[:i]), candidate, strings.TrimSpace(expr[i+len(candidate):])
+			break
+		}
+	}
+	path, err := Compile("$" + lhs[1:])
+	if err != nil {
+		return nil, err
+	}
+	var literal interface{}
+	if op != "" {
+		if literal, err = parseLiteral(rhs); err != nil {
+			return nil, err
+		}
+	}
+
+	matches := func(node interface{}) bool {
+		values := path.Evaluate(node)
+		if op == "" {
+			return len(values) > 0
+		}
+		for _, value := range values {
+			if compare(value, op, literal) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(node interface{}) []interface{} {
+		var selected []interface{}
+		switch n := node.(type) {
+		case []interface{}:
+			for _, element := range n {
+				if matches(element) {
+					selected = append(selected, element)
+				}
+			}
+		case map[string]interface{}:
+			if matches(n) {
+				selected = append(selected, n)
+			}
+		}
+		return selected
+	}, nil
+}
+
+func parseLiteral(s string) (interface{}, error) {
+	switch {
+	case isQuoted(s):
+		return s[1 : len(s)-1], nil
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	case s == "null":
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid literal '%s'", s)
+	}
+	return f, nil
+}
+
+func compare(value interface{}, op string, literal interface{}) bool {
+	switch op {
+	case "==":
+		return value == literal
+	case "!=":
+		return value != literal
+	}
+	switch v := value.(type) {
+	case float64:
+		l, ok := literal.(float64)
+		return ok && compareOrdered(v, l, op)
+	case string:
+		l, ok := literal.(string)
+		return ok && compareOrdered(v, l, op)
+	}
+	return false
+}
+
+func compareOrdered[T float64 | string](a, b T, op string) bool {
+	switch op {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package jsonpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/steinfletcher/bus/internal/jsonpath"
+	"github.com/stretchr/testify/assert"
+)
+
+const document = `{
+	"ID": "1234",
+	"Result": {"name": "Jan", "email": "jan@hey.com"},
+	"Orders": [
+		{"id": 1, "amount": 500, "tags": ["new"]},
+		{"id": 2, "amount": 1500},
+		{"id": 3, "amount": 2500, "tags": []}
+	]
+}`
+
+func TestPath_Evaluate(t *testing.T) {
+	var root interface{}
+	assert.NoError(t, json.Unmarshal([]byte(document), &root))
+
+	tests := map[string]struct {
+		expr     string
+		expected []interface{}
+	}{
+		"root":               {expr: "$", expected: []interface{}{root}},
+		"child":              {expr: "$.ID", expected: []interface{}{"1234"}},
+		"nested child":       {expr: "$.Result.name", expected: []interface{}{"Jan"}},
+		"quoted child":       {expr: "$['Result']['email']", expected: []interface{}{"jan@hey.com"}},
+		"missing child":      {expr: "$.Missing"},
+		"index":              {expr: "$.Orders[1].id", expected: []interface{}{2.0}},
+		"negative index":     {expr: "$.Orders[-1].id", expected: []interface{}{3.0}},
+		"wildcard":           {expr: "$.Orders[*].id", expected: []interface{}{1.0, 2.0, 3.0}},
+		"dot wildcard":       {expr: "$.Orders.*.amount", expected: []interface{}{500.0, 1500.0, 2500.0}},
+		"filter number":      {expr: "$.Orders[?(@.amount > 1000)].id", expected: []interface{}{2.0, 3.0}},
+		"filter equals":      {expr: "$.Orders[?(@.id == 1)].amount", expected: []interface{}{500.0}},
+		"filter exists":      {expr: "$.Orders[?(@.tags)].id", expected: []interface{}{1.0, 3.0}},
+		"filter on object":   {expr: `$[?(@.ID == "1234")].ID`, expected: []interface{}{"1234"}},
+		"filter no match":    {expr: `$[?(@.ID == '5678')]`},
+		"filter not equals":  {expr: `$.Orders[?(@.id != 2)].id`, expected: []interface{}{1.0, 3.0}},
+		"filter nested path": {expr: `$[?(@.Result.name == "Jan")].ID`, expected: []interface{}{"1234"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			path, err := jsonpath.Compile(test.expr)
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.expected, path.Evaluate(root))
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, expr := range []string{"ID", "$.", "$[", "$[abc]", "$[?(ID == 1)]", "$[?(@.ID == abc)]"} {
+		_, err := jsonpath.Compile(expr)
+		assert.Error(t, err, expr)
+	}
+}
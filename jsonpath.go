@@ -0,0 +1,27 @@
+package bus
+
+import (
+	"encoding/json"
+
+	"github.com/steinfletcher/bus/internal/jsonpath"
+)
+
+func (e *eventBus) SubscribeJSONPath(jsonPathExpr string, fn interface{}) error {
+	path, err := jsonpath.Compile(jsonPathExpr)
+	if err != nil {
+		return err
+	}
+	return e.subscribeHandler(fn, handler{
+		accept: func(msg Message) bool {
+			data, err := json.Marshal(msg)
+			if err != nil {
+				return false
+			}
+			var document interface{}
+			if err := json.Unmarshal(data, &document); err != nil {
+				return false
+			}
+			return len(path.Evaluate(document)) > 0
+		},
+	})
+}
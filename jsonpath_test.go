@@ -0,0 +1,34 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_SubscribeJSONPath(t *testing.T) {
+	b := bus.New()
+	var handled []string
+	err := b.SubscribeJSONPath(`$[?(@.ID == "1234")]`, func(ctx context.Context, query *GetUserQuery) error {
+		handled = append(handled, query.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "5678"}))
+
+	assert.Equal(t, []string{"1234"}, handled)
+}
+
+func TestBus_SubscribeJSONPath_InvalidExpression(t *testing.T) {
+	b := bus.New()
+
+	err := b.SubscribeJSONPath("ID", func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	})
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,31 @@
+// Package k8s exposes the health of a bus in the format expected by Kubernetes probes.
+package k8s
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/steinfletcher/bus"
+)
+
+type livenessResponse struct {
+	Status  string   `json:"status"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// LivenessHandler returns a handler for a Kubernetes liveness probe. It responds with 200 OK and {"status":"ok"} when
+// b is healthy, and with 503 Service Unavailable and {"status":"degraded","reasons":[...]} otherwise. See bus.IsHealthy
+// for the conditions that degrade the bus.
+func LivenessHandler(b bus.Bus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := livenessResponse{Status: "ok"}
+		status := http.StatusOK
+		if healthy, reasons := bus.IsHealthy(b); !healthy {
+			response = livenessResponse{Status: "degraded", Reasons: reasons}
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(response)
+	})
+}
@@ -0,0 +1,43 @@
+package k8s_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/k8s"
+	"github.com/stretchr/testify/assert"
+)
+
+type GetUserQuery struct {
+	ID string
+}
+
+func TestLivenessHandler(t *testing.T) {
+	b := bus.New()
+	recorder := httptest.NewRecorder()
+
+	k8s.LivenessHandler(b).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"status":"ok"}`, recorder.Body.String())
+}
+
+func TestLivenessHandler_Degraded(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithCumulativeBudget(&GetUserQuery{}, time.Millisecond, time.Hour))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	})
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+	recorder := httptest.NewRecorder()
+
+	k8s.LivenessHandler(b).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.JSONEq(t, `{"status":"degraded","reasons":["execution budget for *k8s_test.GetUserQuery is exceeded"]}`, recorder.Body.String())
+}
@@ -0,0 +1,132 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+)
+
+// Logger receives the messages logged by the bus, such as errors returned by async handlers. Use WithLogger to set the
+// logger, messages are discarded by default.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// WithLogger sets the logger used by the bus
+func WithLogger(l Logger) Option {
+	return func(e *eventBus) {
+		e.logger = l
+	}
+}
+
+// Verbosity selects the activity of the bus that is logged, see WithVerbosity
+type Verbosity int
+
+const (
+	// VerbosityErrors logs errors returned by async handlers, dropped messages and other failures. It is the default
+	VerbosityErrors Verbosity = iota
+	// VerbositySubscriptions additionally logs each subscription at info level
+	VerbositySubscriptions
+	// VerbosityPublishes additionally logs each publish and each handler invocation with its duration and error at
+	// debug level
+	VerbosityPublishes
+)
+
+// WithVerbosity sets the activity of the bus that is logged to the logger set with WithLogger. Higher verbosities log
+// everything logged by lower verbosities. VerbosityPublishes logs several messages per publish so is best suited to
+// development and debugging.
+func WithVerbosity(v Verbosity) Option {
+	return func(e *eventBus) {
+		e.verbosity = v
+	}
+}
+
+// NoopLogger discards all messages
+type NoopLogger struct{}
+
+func (NoopLogger) Debugf(string, ...interface{}) {}
+func (NoopLogger) Infof(string, ...interface{})  {}
+func (NoopLogger) Warnf(string, ...interface{})  {}
+func (NoopLogger) Errorf(string, ...interface{}) {}
+
+// StdLogger writes messages to a log.Logger prefixed with their level
+type StdLogger struct {
+	logger *log.Logger
+}
+
+// NewStdLogger creates a StdLogger writing to l. The standard logger is used if l is nil
+func NewStdLogger(l *log.Logger) *StdLogger {
+	if l == nil {
+		l = log.Default()
+	}
+	return &StdLogger{logger: l}
+}
+
+func (l *StdLogger) Debugf(format string, args ...interface{}) {
+	l.logger.Printf("DEBUG "+format, args...)
+}
+
+func (l *StdLogger) Infof(format string, args ...interface{}) {
+	l.logger.Printf("INFO "+format, args...)
+}
+
+func (l *StdLogger) Warnf(format string, args ...interface{}) {
+	l.logger.Printf("WARN "+format, args...)
+}
+
+func (l *StdLogger) Errorf(format string, args ...interface{}) {
+	l.logger.Printf("ERROR "+format, args...)
+}
+
+// logSubscription logs the subscription of handler under key if the verbosity includes subscriptions
+func (e *eventBus) logSubscription(handler handler, key string) {
+	if e.verbosity < VerbositySubscriptions {
+		return
+	}
+	mode := "sync"
+	if handler.isAsync {
+		mode = "async"
+	}
+	e.logger.Infof("subscribed %s handler %s to %s", mode, handler.name, key)
+}
+
+// SlogLogger writes messages to a slog.Logger at the corresponding level
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a SlogLogger writing to l. The default slog logger is used if l is nil
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &SlogLogger{logger: l}
+}
+
+func (l *SlogLogger) Debugf(format string, args ...interface{}) {
+	l.log(slog.LevelDebug, format, args)
+}
+
+func (l *SlogLogger) Infof(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, format, args)
+}
+
+func (l *SlogLogger) Warnf(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, format, args)
+}
+
+func (l *SlogLogger) Errorf(format string, args ...interface{}) {
+	l.log(slog.LevelError, format, args)
+}
+
+// log formats the message only if the level is enabled
+func (l *SlogLogger) log(level slog.Level, format string, args []interface{}) {
+	if !l.logger.Enabled(context.Background(), level) {
+		return
+	}
+	l.logger.Log(context.Background(), level, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,162 @@
+package bus_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type testLogger struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+func (l *testLogger) log(level, format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.messages = append(l.messages, level+" "+fmt.Sprintf(format, args...))
+}
+
+func (l *testLogger) Debugf(format string, args ...interface{}) { l.log("DEBUG", format, args...) }
+func (l *testLogger) Infof(format string, args ...interface{})  { l.log("INFO", format, args...) }
+func (l *testLogger) Warnf(format string, args ...interface{})  { l.log("WARN", format, args...) }
+func (l *testLogger) Errorf(format string, args ...interface{}) { l.log("ERROR", format, args...) }
+
+func (l *testLogger) Messages() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.messages...)
+}
+
+func TestBus_WithLogger_AsyncHandlerError(t *testing.T) {
+	logger := &testLogger{}
+	b := bus.NewWithOptions(bus.WithLogger(logger))
+	_ = b.SubscribeAsync(failingHandler)
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Eventually(t, func() bool { return len(logger.Messages()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{
+		"ERROR async handler github.com/steinfletcher/bus_test.failingHandler failed to handle *bus_test.GetUserQuery: user not found",
+	}, logger.Messages())
+}
+
+func TestBus_WithLogger_HandlerNotFound(t *testing.T) {
+	logger := &testLogger{}
+	b := bus.NewWithOptions(bus.WithLogger(logger))
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, []string{"DEBUG no handler found for *bus_test.GetUserQuery"}, logger.Messages())
+}
+
+func TestBus_WithLogger_DeadLetterBusError(t *testing.T) {
+	logger := &testLogger{}
+	b := bus.NewWithOptions(bus.WithLogger(logger), bus.WithDeadLetterBus(bus.New()))
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, []string{
+		"DEBUG no handler found for *bus_test.GetUserQuery",
+		"WARN failed to publish *bus_test.GetUserQuery to the dead letter bus: handler not found",
+	}, logger.Messages())
+}
+
+func TestBus_WithLogger_Degraded(t *testing.T) {
+	logger := &testLogger{}
+	b := bus.NewWithOptions(bus.WithLogger(logger), bus.WithCumulativeBudget(&GetUserQuery{}, time.Millisecond, time.Hour))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		time.Sleep(2 * time.Millisecond)
+		return nil
+	})
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+
+	_, _ = bus.IsHealthy(b)
+
+	assert.Equal(t, []string{"WARN bus is degraded: execution budget for *bus_test.GetUserQuery is exceeded"}, logger.Messages())
+}
+
+func TestBus_WithLogger_WarmupPanic(t *testing.T) {
+	logger := &testLogger{}
+	b := bus.NewWithOptions(bus.WithLogger(logger))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		panic("nil user")
+	})
+
+	_ = bus.Warmup(b, &GetUserQuery{})
+
+	assert.Equal(t, []string{
+		"DEBUG warmup of handler github.com/steinfletcher/bus_test.TestBus_WithLogger_WarmupPanic.func1 recovered from panic: nil user",
+	}, logger.Messages())
+}
+
+func TestStdLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := bus.NewStdLogger(log.New(&out, "", 0))
+
+	logger.Debugf("debug %d", 1)
+	logger.Infof("info %d", 2)
+	logger.Warnf("warn %d", 3)
+	logger.Errorf("error %v", errors.New("4"))
+
+	assert.Equal(t, "DEBUG debug 1\nINFO info 2\nWARN warn 3\nERROR error 4\n", out.String())
+}
+
+func TestBus_WithVerbosity_Subscriptions(t *testing.T) {
+	logger := &testLogger{}
+	b := bus.NewWithOptions(bus.WithLogger(logger), bus.WithVerbosity(bus.VerbositySubscriptions))
+
+	_ = b.Subscribe(getUserHandler)
+	_ = b.SubscribeAsync(failingHandler)
+	_ = b.Publish(context.Background(), &UserResult{})
+
+	assert.Equal(t, []string{
+		"INFO subscribed sync handler github.com/steinfletcher/bus_test.getUserHandler to *bus_test.GetUserQuery",
+		"INFO subscribed async handler github.com/steinfletcher/bus_test.failingHandler to *bus_test.GetUserQuery",
+		"DEBUG no handler found for *bus_test.UserResult",
+	}, logger.Messages())
+}
+
+func TestBus_WithVerbosity_Publishes(t *testing.T) {
+	logger := &testLogger{}
+	b := bus.NewWithOptions(bus.WithLogger(logger), bus.WithVerbosity(bus.VerbosityPublishes))
+	_ = b.Subscribe(failingHandler)
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	messages := logger.Messages()
+	assert.Len(t, messages, 3)
+	assert.Equal(t, "DEBUG publishing *bus_test.GetUserQuery", messages[1])
+	assert.True(t, strings.HasPrefix(messages[2], "DEBUG handler github.com/steinfletcher/bus_test.failingHandler handled *bus_test.GetUserQuery in "))
+	assert.True(t, strings.HasSuffix(messages[2], "error: user not found"))
+}
+
+func TestSlogLogger(t *testing.T) {
+	var out bytes.Buffer
+	logger := bus.NewSlogLogger(slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})))
+
+	logger.Debugf("debug %d", 1)
+	logger.Infof("info %d", 2)
+	logger.Warnf("warn %d", 3)
+	logger.Errorf("error %v", errors.New("4"))
+
+	assert.Equal(t, "level=INFO msg=\"info 2\"\nlevel=WARN msg=\"warn 3\"\nlevel=ERROR msg=\"error 4\"\n", out.String())
+}
@@ -0,0 +1,95 @@
+package bus
+
+import (
+	"sync"
+	"time"
+)
+
+// HandlerMetricsSnapshot summarises the invocations of a handler
+type HandlerMetricsSnapshot struct {
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	AvgDuration time.Duration
+	TotalCalls  uint64
+	// LastError is the last error returned by the handler, or nil if it has not returned an error
+	LastError   error
+	LastErrorAt time.Time
+}
+
+// HandlerMetrics returns the execution metrics of the handler subscribed by sub. Durations are measured from the start
+// to the end of each invocation, excluding time spent waiting in the queue of an async handler. A zero snapshot is
+// returned if sub was not returned by a bus created by New.
+func HandlerMetrics(sub Subscription) HandlerMetricsSnapshot {
+	s, ok := sub.(*subscription)
+	if !ok || s.metrics == nil {
+		return HandlerMetricsSnapshot{}
+	}
+	return s.metrics.Snapshot()
+}
+
+// handlerMetrics records the duration and errors of each invocation of a handler
+type handlerMetrics struct {
+	mu       sync.Mutex
+	snapshot HandlerMetricsSnapshot
+	total    time.Duration
+}
+
+// Record records an invocation that took elapsed and returned err
+func (m *handlerMetrics) Record(elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.snapshot.TotalCalls == 0 || elapsed < m.snapshot.MinDuration {
+		m.snapshot.MinDuration = elapsed
+	}
+	if elapsed > m.snapshot.MaxDuration {
+		m.snapshot.MaxDuration = elapsed
+	}
+	m.snapshot.TotalCalls++
+	m.total += elapsed
+	m.snapshot.AvgDuration = m.total / time.Duration(m.snapshot.TotalCalls)
+	if err != nil {
+		m.snapshot.LastError = err
+		m.snapshot.LastErrorAt = time.Now()
+	}
+}
+
+// Snapshot returns a copy of the metrics
+func (m *handlerMetrics) Snapshot() HandlerMetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.snapshot
+}
+
+// Collector receives the metrics of a bus, for example to export them to Prometheus, see the busprom package. Its
+// methods are called from publishing go routines and async handler go routines so must be safe for concurrent use
+type Collector interface {
+	// Published is called each time a message is published, including messages without handlers
+	Published(msgType string)
+
+	// Handled is called after each invocation of a handler with its duration and the error it returned
+	Handled(msgType, handlerName string, elapsed time.Duration, err error)
+
+	// Dropped is called each time a message is discarded because the queue of an async handler is full, see
+	// BackpressureDropNewest and BackpressureDropOldest
+	Dropped(msgType, handlerName string)
+
+	// QueueDepth is called with the number of messages waiting in the queue of an async handler each time a message
+	// is added to or taken from the queue
+	QueueDepth(msgType, handlerName string, depth int)
+}
+
+// WithMetrics sets collector to receive the metrics of the bus
+func WithMetrics(collector Collector) Option {
+	return func(e *eventBus) {
+		e.collector = collector
+	}
+}
+
+// reportQueue reports the queue depth of an async handler and whether pushing a message to the queue dropped a message
+func (e *eventBus) reportQueue(handler handler, pushErr error) {
+	msgType := handler.messageType().String()
+	if pushErr == errMessageDropped {
+		e.collector.Dropped(msgType, handler.name)
+	}
+	e.collector.QueueDepth(msgType, handler.name, handler.queue.Len())
+}
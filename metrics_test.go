@@ -0,0 +1,116 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandlerMetrics(t *testing.T) {
+	b := bus.New()
+	sub, err := b.SubscribeScheduled(func(ctx context.Context, query *GetUserQuery) error {
+		time.Sleep(time.Duration(len(query.ID)) * time.Millisecond)
+		if len(query.ID) == 5 {
+			return errors.New("user not found")
+		}
+		return nil
+	}, []bus.TimeWindow{{Start: time.Now().Add(-time.Hour), End: time.Now().Add(time.Hour)}})
+	assert.NoError(t, err)
+
+	for i := 1; i <= 10; i++ {
+		_ = b.Publish(context.Background(), &GetUserQuery{ID: strings.Repeat("x", i)})
+	}
+
+	metrics := bus.HandlerMetrics(sub)
+	assert.Equal(t, uint64(10), metrics.TotalCalls)
+	assert.Less(t, metrics.MinDuration, metrics.AvgDuration)
+	assert.Less(t, metrics.AvgDuration, metrics.MaxDuration)
+	assert.GreaterOrEqual(t, metrics.MinDuration, time.Millisecond)
+	assert.GreaterOrEqual(t, metrics.MaxDuration, 10*time.Millisecond)
+	assert.EqualError(t, metrics.LastError, "user not found")
+	assert.WithinDuration(t, time.Now(), metrics.LastErrorAt, time.Second)
+}
+
+func TestHandlerMetrics_NoCalls(t *testing.T) {
+	sub, err := bus.New().SubscribeScheduled(getUserHandler, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, bus.HandlerMetricsSnapshot{}, bus.HandlerMetrics(sub))
+}
+
+// countingCollector counts the metrics it receives
+type countingCollector struct {
+	mu         sync.Mutex
+	published  int
+	handled    int
+	errors     int
+	dropped    int
+	queueDepth int
+}
+
+func (c *countingCollector) Published(msgType string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.published++
+}
+
+func (c *countingCollector) Handled(msgType, handlerName string, elapsed time.Duration, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handled++
+	if err != nil {
+		c.errors++
+	}
+}
+
+func (c *countingCollector) Dropped(msgType, handlerName string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dropped++
+}
+
+func (c *countingCollector) QueueDepth(msgType, handlerName string, depth int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.queueDepth = depth
+}
+
+func TestBus_WithMetrics(t *testing.T) {
+	collector := &countingCollector{}
+	b := bus.NewWithOptions(bus.WithMetrics(collector))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		if query.ID == "" {
+			return errors.New("id is required")
+		}
+		return nil
+	})
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+	_ = b.Publish(context.Background(), &UserResult{})
+
+	assert.Equal(t, 3, collector.published)
+	assert.Equal(t, 2, collector.handled)
+	assert.Equal(t, 1, collector.errors)
+}
+
+func TestBus_WithMetrics_DroppedMessages(t *testing.T) {
+	collector := &countingCollector{}
+	b := bus.NewWithOptions(bus.WithMetrics(collector), bus.WithDefaultQueueSize(2))
+
+	_, err := fillQueue(t, b, bus.WithBackpressure(bus.BackpressureDropNewest))
+
+	assert.NoError(t, err)
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	assert.Equal(t, 4, collector.published)
+	assert.Equal(t, 3, collector.handled)
+	assert.Equal(t, 1, collector.dropped)
+	assert.Equal(t, 0, collector.queueDepth)
+}
@@ -0,0 +1,77 @@
+package bus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// HandlerFunc handles a message. It is the next step in a middleware chain
+type HandlerFunc func(ctx context.Context, msg Message) error
+
+// Middleware intercepts a message before it is handled. It calls next to continue handling the message, optionally
+// with a modified context or message, or returns without calling next to stop the message from being handled.
+type Middleware func(ctx context.Context, msg Message, next HandlerFunc) error
+
+// chain returns a HandlerFunc that calls the middleware in order and then fn
+func chain(fn HandlerFunc, mws ...Middleware) HandlerFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		mw, next := mws[i], fn
+		fn = func(ctx context.Context, msg Message) error {
+			return mw(ctx, msg, next)
+		}
+	}
+	return fn
+}
+
+// WithMiddleware wraps each invocation of the subscribed handler in mws, which run in order after the middleware added
+// to the bus with Use. Other handlers of the same message type are not affected
+//
+//	msgBus.Subscribe(handler, bus.WithMiddleware(timing, authorize))
+func WithMiddleware(mws ...Middleware) SubscribeOption {
+	return func(h *handler) {
+		h.ownMiddleware = append(h.ownMiddleware, mws...)
+	}
+}
+
+// middlewares are the middleware added to a bus with Use
+type middlewares struct {
+	sync.RWMutex
+	items []Middleware
+}
+
+func (m *middlewares) Get() []Middleware {
+	m.RLock()
+	defer m.RUnlock()
+	return m.items
+}
+
+func (e *eventBus) Use(mw Middleware) {
+	e.middleware.Lock()
+	defer e.middleware.Unlock()
+	e.middleware.items = append(e.middleware.items, mw)
+}
+
+// invokeMiddleware invokes the handler through the middleware added with Use. The context and message passed to next
+// by the last middleware replace the first two params. The result holds the error returned by the middleware chain,
+// whether or not the handler returns an error
+func (h handler) invokeMiddleware(mws []Middleware, params []reflect.Value) []reflect.Value {
+	handle := func(ctx context.Context, msg Message) error {
+		withMsg := make([]reflect.Value, len(params))
+		copy(withMsg, params)
+		withMsg[0] = reflect.ValueOf(ctx)
+		if msgValue := reflect.ValueOf(msg); msg != nil && msgValue.Type().AssignableTo(params[1].Type()) {
+			withMsg[1] = msgValue
+		} else {
+			return fmt.Errorf("middleware passed %T to handler %s of %s", msg, h.name, params[1].Type())
+		}
+		return resultError(h.invoke(withMsg))
+	}
+	err := chain(handle, mws...)(params[0].Interface().(context.Context), params[1].Interface())
+	return []reflect.Value{reflect.ValueOf(&err).Elem()}
+}
+
+func (e *eventBus) PublishWith(ctx context.Context, msg Message, mws ...Middleware) error {
+	return chain(e.Publish, mws...)(ctx, msg)
+}
@@ -0,0 +1,183 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type traceKey struct{}
+
+func withTrace(trace string) bus.Middleware {
+	return func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+		return next(context.WithValue(ctx, traceKey{}, trace), msg)
+	}
+}
+
+func TestPublishWith(t *testing.T) {
+	b := bus.New()
+	var traces []interface{}
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		traces = append(traces, ctx.Value(traceKey{}))
+		return nil
+	})
+
+	assert.NoError(t, b.PublishWith(context.Background(), &GetUserQuery{ID: "1234"}, withTrace("abc")))
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	assert.Equal(t, []interface{}{"abc", nil}, traces)
+}
+
+func TestPublishWith_Order(t *testing.T) {
+	b := bus.New()
+	var calls []string
+	record := func(name string) bus.Middleware {
+		return func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+			calls = append(calls, name)
+			return next(ctx, msg)
+		}
+	}
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		calls = append(calls, "handler")
+		return nil
+	})
+
+	assert.NoError(t, b.PublishWith(context.Background(), &GetUserQuery{}, record("first"), record("second")))
+
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestPublishWith_Stop(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		t.Fatal("handler called")
+		return nil
+	})
+
+	err := b.PublishWith(context.Background(), &GetUserQuery{}, func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+		return errSkippable
+	})
+
+	assert.Equal(t, errSkippable, err)
+}
+
+func TestBus_Use(t *testing.T) {
+	b := bus.New()
+	var calls []string
+	b.Use(func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+		calls = append(calls, "first")
+		return next(context.WithValue(ctx, traceKey{}, "global"), msg)
+	})
+	b.Use(func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+		calls = append(calls, "second")
+		return next(ctx, msg)
+	})
+	var traces []interface{}
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		calls = append(calls, "handler")
+		traces = append(traces, ctx.Value(traceKey{}))
+		return nil
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+	assert.Equal(t, []interface{}{"global"}, traces)
+}
+
+func TestBus_Use_PublishWithRunsFirst(t *testing.T) {
+	b := bus.New()
+	var seen interface{}
+	b.Use(func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+		seen = ctx.Value(traceKey{})
+		return next(ctx, msg)
+	})
+	_ = b.Subscribe(getUserHandler)
+
+	assert.NoError(t, b.PublishWith(context.Background(), &GetUserQuery{}, withTrace("abc")))
+
+	assert.Equal(t, "abc", seen)
+}
+
+func TestBus_Use_Error(t *testing.T) {
+	b := bus.New()
+	b.Use(func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+		if query, ok := msg.(*GetUserQuery); ok && query.ID == "" {
+			return errSkippable
+		}
+		return next(ctx, msg)
+	})
+	_ = b.Subscribe(getUserHandler)
+
+	assert.Equal(t, errSkippable, b.Publish(context.Background(), &GetUserQuery{}))
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+}
+
+func TestBus_Use_AsyncError(t *testing.T) {
+	deadLettered := make(chan error, 1)
+	b := bus.NewWithOptions(bus.WithTypedDeadLetterHandlers(map[interface{}]func(ctx context.Context, msg bus.Message, err error){
+		nil: func(ctx context.Context, msg bus.Message, err error) {
+			deadLettered <- err
+		},
+	}))
+	b.Use(func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+		return errSkippable
+	})
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		t.Error("handler called")
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{}))
+
+	assert.Equal(t, errSkippable, <-deadLettered)
+}
+
+func TestBus_Use_WrongMessageType(t *testing.T) {
+	b := bus.New()
+	b.Use(func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+		return next(ctx, GetUserQuery{})
+	})
+	_ = b.Subscribe(getUserHandler)
+
+	err := b.Publish(context.Background(), &GetUserQuery{})
+
+	assert.EqualError(t, err, "middleware passed bus_test.GetUserQuery to handler github.com/steinfletcher/bus_test.getUserHandler of *bus_test.GetUserQuery")
+}
+
+func TestBus_Subscribe_WithMiddleware(t *testing.T) {
+	b := bus.New()
+	var calls []string
+	record := func(name string) bus.Middleware {
+		return func(ctx context.Context, msg bus.Message, next bus.HandlerFunc) error {
+			calls = append(calls, name)
+			return next(ctx, msg)
+		}
+	}
+	b.Use(record("global"))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		calls = append(calls, "first handler")
+		return nil
+	}, bus.WithMiddleware(record("first"), record("second")))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		calls = append(calls, "other handler")
+		return nil
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	assert.Equal(t, []string{"global", "first", "second", "first handler", "global", "other handler"}, calls)
+}
+
+func TestBus_SubscribeAsync_WithMiddleware(t *testing.T) {
+	b := bus.New()
+	traces := make(chan interface{}, 1)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		traces <- ctx.Value(traceKey{})
+	}, bus.WithMiddleware(withTrace("abc")))
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	assert.Equal(t, "abc", <-traces)
+}
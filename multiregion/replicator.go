@@ -0,0 +1,85 @@
+// Package multiregion replicates messages to buses running in several regions. One region is the primary, its result
+// is returned to the publisher. Failures in the other regions are logged so that an outage in a secondary region does
+// not affect the primary.
+package multiregion
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/steinfletcher/bus"
+)
+
+// ErrNoRegions is returned when publishing to a Replicator without regions
+var ErrNoRegions = errors.New("no regions added")
+
+// Replicator publishes each message to the buses of all regions
+type Replicator struct {
+	mu sync.RWMutex
+	// regions is replaced rather than modified when a region is added, as publishes read it without holding mu
+	regions []region
+	logger  bus.Logger
+}
+
+type region struct {
+	name string
+	bus  bus.Bus
+}
+
+// NewReplicator creates a Replicator that logs errors from secondary regions to logger at warn level. The errors are
+// written to the standard logger if logger is nil
+func NewReplicator(logger bus.Logger) *Replicator {
+	if logger == nil {
+		logger = bus.NewStdLogger(nil)
+	}
+	return &Replicator{logger: logger}
+}
+
+// AddRegion adds a region. The first region added is the primary region. Adding a region with the name of an existing
+// region replaces its bus
+func (r *Replicator) AddRegion(name string, b bus.Bus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	regions := make([]region, len(r.regions), len(r.regions)+1)
+	copy(regions, r.regions)
+	defer func() {
+		r.regions = regions
+	}()
+	for i, existing := range regions {
+		if existing.name == name {
+			regions[i].bus = b
+			return
+		}
+	}
+	regions = append(regions, region{name: name, bus: b})
+}
+
+// Publish publishes msg to all regions concurrently and waits for them to complete. The error from the primary region
+// is returned, errors from the other regions are logged
+func (r *Replicator) Publish(ctx context.Context, msg bus.Message) error {
+	r.mu.RLock()
+	regions := r.regions
+	r.mu.RUnlock()
+	if len(regions) == 0 {
+		return ErrNoRegions
+	}
+
+	errs := make([]error, len(regions))
+	var wg sync.WaitGroup
+	for i, region := range regions {
+		wg.Add(1)
+		go func(i int, b bus.Bus) {
+			defer wg.Done()
+			errs[i] = b.Publish(ctx, msg)
+		}(i, region.bus)
+	}
+	wg.Wait()
+
+	for i, err := range errs[1:] {
+		if err != nil {
+			r.logger.Warnf("multiregion: failed to replicate %T to region '%s': %v", msg, regions[i+1].name, err)
+		}
+	}
+	return errs[0]
+}
@@ -0,0 +1,114 @@
+package multiregion_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/multiregion"
+	"github.com/stretchr/testify/assert"
+)
+
+type OrderPlaced struct {
+	ID string
+}
+
+func TestReplicator_Publish(t *testing.T) {
+	var logs bytes.Buffer
+	r := multiregion.NewReplicator(bus.NewStdLogger(log.New(&logs, "", 0)))
+	primary, secondary := bus.New(), bus.New()
+	var primaryOrders, secondaryOrders []string
+	_ = primary.Subscribe(func(ctx context.Context, event *OrderPlaced) error {
+		primaryOrders = append(primaryOrders, event.ID)
+		return nil
+	})
+	_ = secondary.Subscribe(func(ctx context.Context, event *OrderPlaced) error {
+		secondaryOrders = append(secondaryOrders, event.ID)
+		return nil
+	})
+	r.AddRegion("eu-west-1", primary)
+	r.AddRegion("us-east-1", secondary)
+
+	err := r.Publish(context.Background(), &OrderPlaced{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"1234"}, primaryOrders)
+	assert.Equal(t, []string{"1234"}, secondaryOrders)
+	assert.Empty(t, logs.String())
+}
+
+func TestReplicator_Publish_SecondaryFails(t *testing.T) {
+	var logs bytes.Buffer
+	r := multiregion.NewReplicator(bus.NewStdLogger(log.New(&logs, "", 0)))
+	primary, secondary := bus.New(), bus.New()
+	_ = primary.Subscribe(func(ctx context.Context, event *OrderPlaced) error {
+		return nil
+	})
+	_ = secondary.Subscribe(func(ctx context.Context, event *OrderPlaced) error {
+		return errors.New("region unavailable")
+	})
+	r.AddRegion("eu-west-1", primary)
+	r.AddRegion("us-east-1", secondary)
+
+	err := r.Publish(context.Background(), &OrderPlaced{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "WARN multiregion: failed to replicate *multiregion_test.OrderPlaced to region 'us-east-1': region unavailable\n", logs.String())
+}
+
+func TestReplicator_Publish_PrimaryFails(t *testing.T) {
+	r := multiregion.NewReplicator(bus.NoopLogger{})
+	primary := bus.New()
+	_ = primary.Subscribe(func(ctx context.Context, event *OrderPlaced) error {
+		return errors.New("region unavailable")
+	})
+	r.AddRegion("eu-west-1", primary)
+	r.AddRegion("us-east-1", bus.New())
+
+	err := r.Publish(context.Background(), &OrderPlaced{ID: "1234"})
+
+	assert.EqualError(t, err, "region unavailable")
+}
+
+func TestReplicator_AddRegion_ReplacesExisting(t *testing.T) {
+	r := multiregion.NewReplicator(nil)
+	replaced, replacement := bus.New(), bus.New()
+	var handled bool
+	_ = replacement.Subscribe(func(ctx context.Context, event *OrderPlaced) error {
+		handled = true
+		return nil
+	})
+	r.AddRegion("eu-west-1", replaced)
+	r.AddRegion("eu-west-1", replacement)
+
+	err := r.Publish(context.Background(), &OrderPlaced{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.True(t, handled)
+}
+
+func TestReplicator_Publish_NoRegions(t *testing.T) {
+	err := multiregion.NewReplicator(nil).Publish(context.Background(), &OrderPlaced{})
+
+	assert.Equal(t, multiregion.ErrNoRegions, err)
+}
+
+func TestReplicator_AddRegion_ConcurrentPublish(t *testing.T) {
+	r := multiregion.NewReplicator(bus.NoopLogger{})
+	r.AddRegion("eu-west-1", bus.New())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = r.Publish(context.Background(), &OrderPlaced{ID: "1234"})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		r.AddRegion("eu-west-1", bus.New())
+	}
+	<-done
+}
@@ -0,0 +1,20 @@
+package bus
+
+import "reflect"
+
+// ObservePublishes calls fn with each message published to b by type, e.g. with Publish or PublishBatch, until the
+// returned func is called. fn is called when the message is published, before it is dispatched to handlers, so
+// messages without handlers are observed too. Messages published with PublishTopic, PublishRaw and Request are not
+// observed. fn is called on the publishing go routine and must not call the returned func.
+func ObservePublishes(b Bus, fn func(msg Message)) (stop func()) {
+	observer, ok := b.(publishObserver)
+	if !ok {
+		return func() {}
+	}
+	id := observer.observePublish(func(msgTypeName string, msg Message) {
+		if msgTypeName == reflect.TypeOf(msg).String() {
+			fn(msg)
+		}
+	})
+	return func() { observer.unobservePublish(id) }
+}
@@ -0,0 +1,26 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObservePublishes(t *testing.T) {
+	b := bus.New()
+	var observed []bus.Message
+
+	stop := bus.ObservePublishes(b, func(msg bus.Message) {
+		observed = append(observed, msg)
+	})
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1"})
+	_ = b.PublishTopic(context.Background(), "users.get", &GetUserQuery{ID: "2"})
+	_ = b.PublishRaw(context.Background(), "GetUserQuery", []byte(`{"ID":"3"}`))
+	_, _ = b.Request(context.Background(), &GetUserQuery{ID: "4"})
+	stop()
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "5"})
+
+	assert.Equal(t, []bus.Message{&GetUserQuery{ID: "1"}}, observed)
+}
@@ -0,0 +1,43 @@
+package bus
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+)
+
+// PanicError is returned in place of the error of a handler that panicked, see WithPanicRecovery
+type PanicError struct {
+	// HandlerName is the name of the handler that panicked
+	HandlerName string
+	// Value is the value passed to panic
+	Value interface{}
+	// Stack is the stack trace of the go routine that panicked
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("handler %s panicked: %v", e.HandlerName, e.Value)
+}
+
+// WithPanicRecovery enables or disables the recovery of handlers that panic, which is enabled by default. A recovered
+// panic is converted to a *PanicError. Sync handlers return the error to the publisher and async handlers pass it to
+// the callbacks for failed async handlers, such as WithHandlerErrorLogger and WithDeadLetterBus.
+//
+// When recovery is disabled a panicking handler crashes the program, unless it is an async handler and
+// WithRestartBackoff is used.
+func WithPanicRecovery(enabled bool) Option {
+	return func(e *eventBus) {
+		e.panicRecovery = enabled
+	}
+}
+
+// recoverPanic converts a panic of the handler into a result holding a *PanicError. It must be deferred
+func (h handler) recoverPanic(result *[]reflect.Value) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	var err error = &PanicError{HandlerName: h.name, Value: r, Stack: debug.Stack()}
+	*result = []reflect.Value{reflect.ValueOf(&err).Elem()}
+}
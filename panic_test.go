@@ -0,0 +1,56 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func panickingHandler(ctx context.Context, query *GetUserQuery) error {
+	panic("nil user")
+}
+
+func TestBus_PanicRecovery_Sync(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(panickingHandler)
+
+	err := b.Publish(context.Background(), &GetUserQuery{})
+
+	var panicErr *bus.PanicError
+	assert.True(t, errors.As(err, &panicErr))
+	assert.EqualError(t, err, "handler github.com/steinfletcher/bus_test.panickingHandler panicked: nil user")
+	assert.Equal(t, "nil user", panicErr.Value)
+	assert.Contains(t, string(panicErr.Stack), "panickingHandler")
+}
+
+func TestBus_PanicRecovery_Async(t *testing.T) {
+	failed := make(chan error, 2)
+	b := bus.NewWithOptions(bus.WithHandlerErrorLogger(func(msgType, handlerName, file string, line int, err error) {
+		failed <- err
+	}))
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		if query.ID == "" {
+			panic("nil user")
+		}
+		failed <- nil
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{}))
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	var panicErr *bus.PanicError
+	assert.True(t, errors.As(<-failed, &panicErr))
+	assert.NoError(t, <-failed)
+}
+
+func TestBus_WithPanicRecovery_Disabled(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithPanicRecovery(false))
+	_ = b.Subscribe(panickingHandler)
+
+	assert.PanicsWithValue(t, "nil user", func() {
+		_ = b.Publish(context.Background(), &GetUserQuery{})
+	})
+}
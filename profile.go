@@ -0,0 +1,40 @@
+package bus
+
+import (
+	"context"
+	"reflect"
+	"runtime/pprof"
+	"sync"
+)
+
+// ProfileLabelKey is the pprof label that holds the handler name while profile labels are enabled
+const ProfileLabelKey = "bus_handler"
+
+// EnableProfileLabels sets the pprof label ProfileLabelKey to the handler name for the duration of each handler
+// invocation, so samples in CPU profiles can be attributed to the handlers of b. Call the returned func to disable
+// labelling. Labels are added to the handler context, so messages published from within a handler are also labelled.
+func EnableProfileLabels(b Bus) (disable func()) {
+	e, ok := b.(*eventBus)
+	if !ok {
+		return func() {}
+	}
+	e.profileLabels.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.profileLabels.Add(-1)
+		})
+	}
+}
+
+func (h handler) callLabelled(params []reflect.Value) []reflect.Value {
+	var results []reflect.Value
+	ctx := params[0].Interface().(context.Context)
+	pprof.Do(ctx, pprof.Labels(ProfileLabelKey, h.name), func(ctx context.Context) {
+		labelled := make([]reflect.Value, len(params))
+		copy(labelled, params)
+		labelled[0] = reflect.ValueOf(ctx)
+		results = h.Handler.Call(labelled)
+	})
+	return results
+}
@@ -0,0 +1,29 @@
+// Package profile captures CPU profiles that attribute samples to the handlers of a bus.
+package profile
+
+import (
+	"bytes"
+	"runtime/pprof"
+	"time"
+
+	gprofile "github.com/google/pprof/profile"
+	"github.com/steinfletcher/bus"
+)
+
+// CollectHandlerFlameData records a CPU profile for d while the handlers of b are labelled with their names. Samples
+// taken while a handler runs carry the label bus.ProfileLabelKey, which can be used to focus the flame graph in
+// go tool pprof, e.g. go tool pprof -http=:8080 -tagfocus=bus_handler=... cpu.pprof
+//
+// The bus is not driven by this function so publish messages from another goroutine while it runs. An error is
+// returned if a CPU profile is already being recorded, only one can be active per process.
+func CollectHandlerFlameData(b bus.Bus, d time.Duration) (*gprofile.Profile, error) {
+	var buf bytes.Buffer
+	if err := pprof.StartCPUProfile(&buf); err != nil {
+		return nil, err
+	}
+	disable := bus.EnableProfileLabels(b)
+	time.Sleep(d)
+	disable()
+	pprof.StopCPUProfile()
+	return gprofile.Parse(&buf)
+}
@@ -0,0 +1,68 @@
+package profile_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/profile"
+	"github.com/stretchr/testify/assert"
+)
+
+type HashCommand struct {
+	Data []byte
+}
+
+func hashHandler(ctx context.Context, cmd *HashCommand) error {
+	sum := sha256.Sum256(cmd.Data)
+	for i := 0; i < 1000; i++ {
+		sum = sha256.Sum256(sum[:])
+	}
+	return nil
+}
+
+func TestCollectHandlerFlameData(t *testing.T) {
+	b := bus.New()
+	assert.NoError(t, b.Subscribe(hashHandler))
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_ = b.Publish(context.Background(), &HashCommand{Data: []byte("data")})
+			}
+		}
+	}()
+
+	p, err := profile.CollectHandlerFlameData(b, 500*time.Millisecond)
+	close(done)
+	<-stopped
+
+	assert.NoError(t, err)
+	var labelled int
+	for _, sample := range p.Sample {
+		for _, value := range sample.Label[bus.ProfileLabelKey] {
+			if value == "github.com/steinfletcher/bus/profile_test.hashHandler" {
+				labelled++
+			}
+		}
+	}
+	assert.NotZero(t, labelled)
+}
+
+func TestCollectHandlerFlameData_ProfileAlreadyActive(t *testing.T) {
+	assert.NoError(t, pprof.StartCPUProfile(io.Discard))
+	defer pprof.StopCPUProfile()
+
+	_, err := profile.CollectHandlerFlameData(bus.New(), time.Millisecond)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package bus_test
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnableProfileLabels(t *testing.T) {
+	b := bus.New()
+	var labels []string
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		label, _ := pprof.Label(ctx, bus.ProfileLabelKey)
+		labels = append(labels, label)
+		return nil
+	})
+
+	disable := bus.EnableProfileLabels(b)
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+	disable()
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+
+	assert.Equal(t, []string{"github.com/steinfletcher/bus_test.TestEnableProfileLabels.func1", ""}, labels)
+}
+
+func TestEnableProfileLabels_DisableConcurrently(t *testing.T) {
+	b := bus.New()
+	var labels []string
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		label, _ := pprof.Label(ctx, bus.ProfileLabelKey)
+		labels = append(labels, label)
+		return nil
+	})
+	enabled := bus.EnableProfileLabels(b)
+	defer enabled()
+
+	disable := bus.EnableProfileLabels(b)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			disable()
+		}()
+	}
+	wg.Wait()
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+
+	assert.Equal(t, []string{"github.com/steinfletcher/bus_test.TestEnableProfileLabels_DisableConcurrently.func1"}, labels)
+}
@@ -0,0 +1,76 @@
+package bus
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// publishRateWindow is the sliding window over which the publish rate is measured by WithPublishRateAlert
+const publishRateWindow = 10
+
+// WithPublishRateAlert calls alertFn with the current publish rate when the number of messages published per second
+// drops below minRPS, having previously been at or above it. The rate is measured once per second over a sliding
+// 10 second window, so alertFn is called up to 10 seconds after publishing stops. alertFn is called again only after
+// the rate has recovered to minRPS. The monitor stops when the bus is closed.
+func WithPublishRateAlert(minRPS float64, alertFn func(currentRPS float64)) Option {
+	return func(e *eventBus) {
+		ticker := time.NewTicker(time.Second)
+		withPublishRateAlert(minRPS, alertFn, time.Second, ticker.C)(e)
+		go func() {
+			<-e.done
+			ticker.Stop()
+		}()
+	}
+}
+
+// withPublishRateAlert is WithPublishRateAlert measuring the rate each time ticks receives, which must be every
+// interval
+func withPublishRateAlert(minRPS float64, alertFn func(currentRPS float64), interval time.Duration, ticks <-chan time.Time) Option {
+	return func(e *eventBus) {
+		monitor := &publishRateMonitor{minRPS: minRPS, alert: alertFn, interval: interval}
+		e.observePublish(monitor.record)
+		go monitor.run(ticks, e.done)
+	}
+}
+
+// publishRateMonitor counts published messages and alerts when the publish rate drops below the minimum
+type publishRateMonitor struct {
+	minRPS   float64
+	alert    func(currentRPS float64)
+	interval time.Duration
+	count    atomic.Uint64
+}
+
+func (m *publishRateMonitor) record(string, Message) {
+	m.count.Add(1)
+}
+
+func (m *publishRateMonitor) run(tick <-chan time.Time, done <-chan struct{}) {
+	var buckets [publishRateWindow]uint64
+	var ticks int
+	var above bool
+	for {
+		select {
+		case <-tick:
+		case <-done:
+			return
+		}
+		buckets[ticks%publishRateWindow] = m.count.Swap(0)
+		ticks++
+		n := ticks
+		if n > publishRateWindow {
+			n = publishRateWindow
+		}
+		var total uint64
+		for _, count := range buckets {
+			total += count
+		}
+		rate := float64(total) / (float64(n) * m.interval.Seconds())
+		if rate >= m.minRPS {
+			above = true
+		} else if above {
+			above = false
+			m.alert(rate)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPublishRateAlert(t *testing.T) {
+	alerts := make(chan float64, 1)
+	ticks := make(chan time.Time)
+	b := bus.NewWithOptions(bus.WithPublishRateAlertTicks(5, func(currentRPS float64) {
+		alerts <- currentRPS
+	}, time.Second, ticks))
+	defer b.Close(context.Background())
+	_ = b.Subscribe(getUserHandler)
+
+	for i := 0; i < 100; i++ {
+		_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	}
+	// the 100 messages stay in the 10 second window for 10 ticks, at 10 per second on the 10th
+	for i := 0; i < 10; i++ {
+		ticks <- time.Time{}
+	}
+	assert.Empty(t, alerts)
+	ticks <- time.Time{}
+
+	select {
+	case rate := <-alerts:
+		assert.Equal(t, 0.0, rate)
+	case <-time.After(time.Second):
+		t.Fatal("alert not called")
+	}
+}
+
+func TestWithPublishRateAlert_StopsOnClose(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithPublishRateAlert(5, func(currentRPS float64) {}))
+
+	assert.NoError(t, b.Close(context.Background()))
+}
@@ -0,0 +1,287 @@
+package bus
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// asyncQueue passes messages from publishers to the go routine running an async handler. The underlying channel
+// can be replaced with a larger one while the queue is in use, see WithAdaptiveQueue.
+type asyncQueue struct {
+	sync.RWMutex
+	gen *queueGeneration
+	// pending is the number of messages that are queued or being handled
+	pending   int64
+	policy    BackpressurePolicy
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// queueGeneration is a channel used by the queue. The retired channel is closed when the queue starts replacing the
+// channel so that blocked publishers and the handler go routine switch to the new channel.
+type queueGeneration struct {
+	ch      chan []reflect.Value
+	retired chan struct{}
+}
+
+func newAsyncQueue(size int, policy BackpressurePolicy) *asyncQueue {
+	return &asyncQueue{
+		gen:    newQueueGeneration(size),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+}
+
+func newQueueGeneration(size int) *queueGeneration {
+	return &queueGeneration{
+		ch:      make(chan []reflect.Value, size),
+		retired: make(chan struct{}),
+	}
+}
+
+// Push adds the params to the queue. If the queue is full the queue policy decides whether Push blocks until there is
+// room, discards a message and returns errMessageDropped, or returns ErrQueueFull. The params are discarded if the queue
+// is closed
+func (q *asyncQueue) Push(params []reflect.Value) error {
+	atomic.AddInt64(&q.pending, 1)
+	var err error
+	for {
+		q.RLock()
+		gen := q.gen
+		// checked first as a send to a channel with room is chosen as often as a closed queue by the select below
+		select {
+		case <-q.done:
+			q.RUnlock()
+			atomic.AddInt64(&q.pending, -1)
+			return err
+		default:
+		}
+		if q.policy != BackpressureBlock {
+			select {
+			case gen.ch <- params:
+				q.RUnlock()
+				return err
+			default:
+			}
+			switch q.policy {
+			case BackpressureDropNewest:
+				q.RUnlock()
+				atomic.AddInt64(&q.pending, -1)
+				return errMessageDropped
+			case BackpressureError:
+				q.RUnlock()
+				atomic.AddInt64(&q.pending, -1)
+				return ErrQueueFull
+			case BackpressureDropOldest:
+				// the read lock is held so that the queue is not resized while the oldest message is dropped
+				select {
+				case <-gen.ch:
+					atomic.AddInt64(&q.pending, -1)
+					err = errMessageDropped
+				default:
+				}
+				q.RUnlock()
+				continue
+			}
+		}
+		select {
+		case gen.ch <- params:
+			q.RUnlock()
+			return err
+		case <-gen.retired:
+			q.RUnlock()
+		case <-q.done:
+			q.RUnlock()
+			atomic.AddInt64(&q.pending, -1)
+			return err
+		}
+	}
+}
+
+// Consume calls fn for each message in the queue until the queue is closed. It blocks so should be run in its own go
+// routine. Several go routines may consume the same queue
+func (q *asyncQueue) Consume(fn func(params []reflect.Value)) {
+	for {
+		q.RLock()
+		gen := q.gen
+		q.RUnlock()
+		select {
+		case params := <-gen.ch:
+			fn(params)
+			atomic.AddInt64(&q.pending, -1)
+		case <-gen.retired:
+		case <-q.done:
+			return
+		}
+	}
+}
+
+// Drain waits until all queued messages have been handled. It returns false if the queue is not empty after timeout
+func (q *asyncQueue) Drain(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for atomic.LoadInt64(&q.pending) > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return true
+}
+
+// Len returns the number of messages waiting in the queue, excluding messages being handled
+func (q *asyncQueue) Len() int {
+	q.RLock()
+	defer q.RUnlock()
+	return len(q.gen.ch)
+}
+
+// Empty reports whether all queued messages have been handled
+func (q *asyncQueue) Empty() bool {
+	return atomic.LoadInt64(&q.pending) == 0
+}
+
+// Close stops the go routine consuming the queue. Messages that have not been handled are discarded
+func (q *asyncQueue) Close() {
+	q.closeOnce.Do(func() {
+		close(q.done)
+	})
+}
+
+// Resize replaces the channel with a channel of the given size and migrates the queued messages to it. size must not
+// be less than the current capacity
+func (q *asyncQueue) Resize(size int) {
+	q.RLock()
+	old := q.gen
+	q.RUnlock()
+	close(old.retired)
+
+	q.Lock()
+	defer q.Unlock()
+	gen := newQueueGeneration(size)
+	// consumers may take messages from the retired channel concurrently, so the channel is not read once it is empty
+migrate:
+	for {
+		select {
+		case params := <-old.ch:
+			gen.ch <- params
+		default:
+			break migrate
+		}
+	}
+	q.gen = gen
+}
+
+// FillRatio returns the fraction of the queue capacity in use
+func (q *asyncQueue) FillRatio() float64 {
+	q.RLock()
+	defer q.RUnlock()
+	return float64(len(q.gen.ch)) / float64(cap(q.gen.ch))
+}
+
+// Cap returns the capacity of the queue
+func (q *asyncQueue) Cap() int {
+	q.RLock()
+	defer q.RUnlock()
+	return cap(q.gen.ch)
+}
+
+// WithWorkers sets the number of go routines that handle the messages of an async handler, so that up to n messages
+// are handled concurrently. Messages may then be handled out of the order they were published. The default is a
+// single go routine. It has no effect on sync handlers
+func WithWorkers(n int) SubscribeOption {
+	return func(h *handler) {
+		h.workers = n
+	}
+}
+
+// WithQueueSize sets the size of the queue of an async handler, overriding WithDefaultQueueSize. The queue keeps this
+// size when the bus is created with WithAdaptiveQueue. It has no effect on sync handlers
+func WithQueueSize(n int) SubscribeOption {
+	return func(h *handler) {
+		h.queueSize = n
+	}
+}
+
+// adaptiveQueueConfig configures queues that grow when they are consistently near full
+type adaptiveQueueConfig struct {
+	minSize        int
+	maxSize        int
+	growthFactor   float64
+	sampleInterval time.Duration
+}
+
+// adaptiveQueueThreshold is the fill ratio above which a queue is considered near full
+const adaptiveQueueThreshold = 0.8
+
+// WithAdaptiveQueue grows the queues of async subscribers when they are consistently near full. Queues start with
+// minSize capacity. When a queue is more than 80% full for sampleInterval its capacity is multiplied by
+// growthFactor, up to maxSize. Queued messages are migrated to the larger queue without being reordered. It panics if
+// minSize is less than 1, maxSize is less than minSize, growthFactor is not greater than 1 or sampleInterval is not
+// positive.
+func WithAdaptiveQueue(minSize, maxSize int, growthFactor float64, sampleInterval time.Duration) Option {
+	switch {
+	case minSize < 1:
+		panic(fmt.Sprintf("adaptive queue min size must be at least 1, got %d", minSize))
+	case maxSize < minSize:
+		panic(fmt.Sprintf("adaptive queue max size %d is less than min size %d", maxSize, minSize))
+	case !(growthFactor > 1):
+		panic(fmt.Sprintf("adaptive queue growth factor must be greater than 1, got %v", growthFactor))
+	case sampleInterval <= 0:
+		panic(fmt.Sprintf("adaptive queue sample interval must be positive, got %s", sampleInterval))
+	}
+	return func(e *eventBus) {
+		e.adaptiveQueue = &adaptiveQueueConfig{
+			minSize:        minSize,
+			maxSize:        maxSize,
+			growthFactor:   growthFactor,
+			sampleInterval: sampleInterval,
+		}
+	}
+}
+
+// monitor grows the queue until it reaches the maximum size
+func (c adaptiveQueueConfig) monitor(q *asyncQueue) {
+	// the fill ratio is sampled several times per interval, or once for intervals too short to divide
+	tick := c.sampleInterval / 4
+	if tick <= 0 {
+		tick = c.sampleInterval
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+	var nearFullSince time.Time
+	for {
+		var now time.Time
+		select {
+		case now = <-ticker.C:
+		case <-q.done:
+			return
+		}
+		if q.FillRatio() < adaptiveQueueThreshold {
+			nearFullSince = time.Time{}
+			continue
+		}
+		if nearFullSince.IsZero() {
+			nearFullSince = now
+			continue
+		}
+		if now.Sub(nearFullSince) < c.sampleInterval {
+			continue
+		}
+		size := int(math.Ceil(float64(q.Cap()) * c.growthFactor))
+		if size > c.maxSize {
+			size = c.maxSize
+		}
+		if size <= q.Cap() {
+			return
+		}
+		q.Resize(size)
+		if size >= c.maxSize {
+			return
+		}
+		nearFullSince = time.Time{}
+	}
+}
@@ -0,0 +1,130 @@
+package bus_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_WithAdaptiveQueue(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithAdaptiveQueue(4, 64, 2, 20*time.Millisecond))
+	release := make(chan struct{})
+	received := make(chan string, 20)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		<-release
+		received <- query.ID
+	})
+
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for i := 0; i < 20; i++ {
+			_ = b.Publish(context.Background(), &GetUserQuery{ID: string(rune('a' + i))})
+		}
+	}()
+
+	select {
+	case <-published:
+	case <-time.After(5 * time.Second):
+		t.Fatal("queue did not grow")
+	}
+	close(release)
+
+	var ids string
+	for i := 0; i < 20; i++ {
+		ids += <-received
+	}
+	assert.Equal(t, "abcdefghijklmnopqrst", ids)
+}
+
+func TestBus_WithAdaptiveQueue_ShortSampleInterval(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithAdaptiveQueue(1, 4, 2, time.Nanosecond))
+	defer b.Close(context.Background())
+	received := make(chan string, 1)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		received <- query.ID
+	})
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	assert.Equal(t, "1234", <-received)
+}
+
+func TestWithAdaptiveQueue_InvalidArguments(t *testing.T) {
+	assert.PanicsWithValue(t, "adaptive queue min size must be at least 1, got 0", func() {
+		bus.WithAdaptiveQueue(0, 4, 2, time.Second)
+	})
+	assert.PanicsWithValue(t, "adaptive queue max size 2 is less than min size 4", func() {
+		bus.WithAdaptiveQueue(4, 2, 2, time.Second)
+	})
+	assert.PanicsWithValue(t, "adaptive queue growth factor must be greater than 1, got 1", func() {
+		bus.WithAdaptiveQueue(1, 4, 1, time.Second)
+	})
+	assert.PanicsWithValue(t, "adaptive queue sample interval must be positive, got 0s", func() {
+		bus.WithAdaptiveQueue(1, 4, 2, 0)
+	})
+}
+
+func TestBus_SubscribeAsync_WithWorkers(t *testing.T) {
+	b := bus.New()
+	var running, maxRunning atomic.Int32
+	var wg sync.WaitGroup
+	wg.Add(8)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		defer wg.Done()
+		n := running.Add(1)
+		for {
+			max := maxRunning.Load()
+			if n <= max || maxRunning.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+	}, bus.WithWorkers(4))
+
+	start := time.Now()
+	for i := 0; i < 8; i++ {
+		assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(4), maxRunning.Load())
+	assert.Less(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestBus_SubscribeAsync_WithQueueSize(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDefaultQueueSize(1), bus.WithDefaultBackpressure(bus.BackpressureError))
+	release := make(chan struct{})
+	defer close(release)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		<-release
+	}, bus.WithQueueSize(5))
+
+	var err error
+	var published int
+	for ; published < 10 && err == nil; published++ {
+		err = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	}
+
+	assert.ErrorIs(t, err, bus.ErrQueueFull)
+	// the publish that fails follows five queued messages and at most one that is being handled
+	assert.GreaterOrEqual(t, published, 6)
+	assert.LessOrEqual(t, published, 7)
+}
+
+func TestAsyncQueue_PushAfterClose(t *testing.T) {
+	for _, policy := range []bus.BackpressurePolicy{bus.BackpressureBlock, bus.BackpressureDropNewest, bus.BackpressureDropOldest, bus.BackpressureError} {
+		q := bus.NewAsyncQueue(1, policy)
+		q.Close()
+
+		assert.NoError(t, q.Push(nil))
+		assert.True(t, q.Drain(10*time.Millisecond), policy)
+		assert.Equal(t, 0, q.Len())
+	}
+}
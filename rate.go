@@ -0,0 +1,57 @@
+package bus
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// rateWindow is the time constant of the invocation rate moving average
+const rateWindow = time.Minute
+
+// rateMeter measures the invocation rate of a handler as an exponentially weighted moving average. Each invocation
+// adds 1/window to the rate, which decays by e^(-elapsed/window). The rate is divided by the weight of the time since
+// the meter started, so that a meter younger than the window reports the rate it has observed rather than ramping up
+// from zero.
+type rateMeter struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	start   time.Time
+	last    time.Time
+	decayed float64
+}
+
+func newRateMeter() *rateMeter {
+	return newRateMeterWithClock(time.Now)
+}
+
+// newRateMeterWithClock returns a rateMeter that reads the time from now
+func newRateMeterWithClock(now func() time.Time) *rateMeter {
+	start := now()
+	return &rateMeter{now: now, start: start, last: start}
+}
+
+// Mark records an invocation
+func (m *rateMeter) Mark() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	m.decayed = m.decayed*decay(now.Sub(m.last)) + 1/rateWindow.Seconds()
+	m.last = now
+}
+
+// Rate returns the moving average of the number of invocations per second
+func (m *rateMeter) Rate() float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	weight := 1 - decay(now.Sub(m.start))
+	if weight <= 0 {
+		return 0
+	}
+	return m.decayed * decay(now.Sub(m.last)) / weight
+}
+
+func decay(elapsed time.Duration) float64 {
+	return math.Exp(-elapsed.Seconds() / rateWindow.Seconds())
+}
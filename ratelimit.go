@@ -0,0 +1,29 @@
+package bus
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// WithSubscriptionRateLimit limits the rate at which handlers can be subscribed to rps subscriptions per second,
+// with bursts of one. Subscribe methods block until the subscription is allowed. Use SubscribeCtx to give up waiting
+// when a context is done.
+func WithSubscriptionRateLimit(rps float64) Option {
+	return func(e *eventBus) {
+		e.subscriptionLimiter = rate.NewLimiter(rate.Limit(rps), 1)
+	}
+}
+
+func (e *eventBus) SubscribeCtx(ctx context.Context, fn interface{}) error {
+	_, err := e.subscribeHandleCtx(ctx, fn, handler{})
+	return err
+}
+
+// waitSubscription blocks until the subscription rate limit allows another subscription or ctx is done
+func (e *eventBus) waitSubscription(ctx context.Context) error {
+	if e.subscriptionLimiter == nil {
+		return nil
+	}
+	return e.subscriptionLimiter.Wait(ctx)
+}
@@ -0,0 +1,51 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_WithSubscriptionRateLimit(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithSubscriptionRateLimit(20))
+	start := time.Now()
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, b.Subscribe(getUserHandler))
+		assert.NoError(t, b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {}))
+	}
+
+	// the first subscription uses the burst, the remaining 9 wait 50ms each
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, 400*time.Millisecond)
+	assert.Less(t, elapsed, 800*time.Millisecond)
+	assert.Len(t, b.Subscriptions(), 10)
+}
+
+func TestBus_SubscribeCtx_Cancelled(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithSubscriptionRateLimit(1))
+	assert.NoError(t, b.Subscribe(getUserHandler))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := b.SubscribeCtx(ctx, getUserHandler)
+
+	assert.Error(t, err)
+	assert.Len(t, b.Subscriptions(), 1)
+}
+
+func TestBus_SubscribeCtx(t *testing.T) {
+	b := bus.New()
+	var called bool
+	err := b.SubscribeCtx(context.Background(), func(ctx context.Context, query *GetUserQuery) error {
+		called = true
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{}))
+	assert.True(t, called)
+}
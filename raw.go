@@ -0,0 +1,91 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Codec decodes the data of messages published with PublishRaw
+type Codec interface {
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONCodec decodes JSON encoded messages
+type JSONCodec struct{}
+
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// rawMessage is the message dispatched to handlers subscribed with SubscribeRaw
+type rawMessage []byte
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+func (e *eventBus) SubscribeRaw(msgTypeName string, codec Codec, fn interface{}, opts ...SubscribeOption) error {
+	if err := validateHandler(fn); err != nil {
+		return err
+	}
+	fnType := reflect.TypeOf(fn)
+	if fnType.NumOut() != 1 || fnType.Out(0) != errorType {
+		return errors.New("raw handlers must return an error")
+	}
+	msgType := fnType.In(1)
+	if err := e.waitSubscription(context.Background()); err != nil {
+		return err
+	}
+	if e.closed.Load() {
+		return ErrBusClosed
+	}
+
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		in[i] = fnType.In(i)
+	}
+	in[1] = reflect.TypeOf(rawMessage(nil))
+	handlerFn := reflect.ValueOf(fn)
+	wrapped := reflect.MakeFunc(reflect.FuncOf(in, []reflect.Type{errorType}, false), func(args []reflect.Value) []reflect.Value {
+		msg := reflect.New(msgType)
+		if msgType.Kind() == reflect.Ptr {
+			msg = reflect.New(msgType.Elem())
+		}
+		if err := codec.Unmarshal(args[1].Bytes(), msg.Interface()); err != nil {
+			return []reflect.Value{reflect.ValueOf(&err).Elem()}
+		}
+		if msgType.Kind() != reflect.Ptr {
+			msg = msg.Elem()
+		}
+		params := make([]reflect.Value, len(args))
+		copy(params, args)
+		params[1] = msg
+		return handlerFn.Call(params)
+	})
+
+	key := rawKey(msgTypeName)
+	h := e.newHandler(wrapped.Interface(), withOptions(handler{source: fn, msgType: msgType, codec: codec}, opts))
+	if _, err := e.handlers.AddChecked(key, h, e.duplicates != nil); err != nil {
+		h.stop()
+		if err != ErrAlreadySubscribed || !e.duplicates.ignore {
+			return fmt.Errorf("%w: raw handler %s for '%s'", err, h.name, msgTypeName)
+		}
+		return nil
+	}
+	e.logSubscription(h, key)
+	return nil
+}
+
+func (e *eventBus) PublishRaw(ctx context.Context, msgTypeName string, data []byte) error {
+	return e.publishKey(ctx, rawKey(msgTypeName), rawMessage(data), stopOnError, publishDefault)
+}
+
+// rawKeyPrefix prefixes the handler keys of raw handlers
+const rawKeyPrefix = "raw:"
+
+// rawKey returns the handler key of raw handlers for the named message type. Raw handlers are keyed separately from
+// handlers subscribed to the message type, because the two are published with different message values
+func rawKey(msgTypeName string) string {
+	return rawKeyPrefix + msgTypeName
+}
@@ -0,0 +1,110 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_PublishRaw(t *testing.T) {
+	b := bus.New()
+	var received *GetUserQuery
+	err := b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, func(ctx context.Context, query *GetUserQuery) error {
+		received = query
+		return nil
+	})
+	assert.NoError(t, err)
+
+	err = b.PublishRaw(context.Background(), "GetUserQuery", []byte(`{"ID":"1234","Result":{"name":"Jan"}}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, &GetUserQuery{ID: "1234", Result: UserResult{Name: "Jan"}}, received)
+}
+
+func TestBus_PublishRaw_NonPointerMessage(t *testing.T) {
+	b := bus.New()
+	var received GetUserQuery
+	_ = b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, func(ctx context.Context, query GetUserQuery) error {
+		received = query
+		return nil
+	})
+
+	err := b.PublishRaw(context.Background(), "GetUserQuery", []byte(`{"ID":"1234"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, GetUserQuery{ID: "1234"}, received)
+}
+
+func TestBus_PublishRaw_DecodeError(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	})
+
+	err := b.PublishRaw(context.Background(), "GetUserQuery", []byte(`{"ID":`))
+
+	assert.EqualError(t, err, "unexpected end of JSON input")
+}
+
+func TestBus_PublishRaw_HandlerNotFound(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	})
+
+	err := b.PublishRaw(context.Background(), "*bus_test.GetUserQuery", []byte(`{}`))
+
+	assert.Equal(t, bus.ErrHandlerNotFound, err)
+}
+
+func TestBus_SubscribeRaw_RequiresErrorResult(t *testing.T) {
+	b := bus.New()
+
+	err := b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, func(ctx context.Context, query *GetUserQuery) {})
+
+	assert.EqualError(t, err, "raw handlers must return an error")
+}
+
+func TestBus_SubscribeRaw_Subscriptions(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, getUserHandler)
+
+	subscriptions := b.Subscriptions()
+
+	assert.Len(t, subscriptions, 1)
+	assert.Equal(t, "*bus_test.GetUserQuery", subscriptions[0].MessageType.String())
+	assert.Equal(t, "github.com/steinfletcher/bus_test.getUserHandler", subscriptions[0].HandlerName)
+}
+
+func TestBus_SubscribeRaw_SingleHandler(t *testing.T) {
+	b := bus.New()
+	handle := func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	}
+	assert.NoError(t, b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, handle, bus.WithSingleHandler()))
+
+	err := b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, handle)
+
+	assert.True(t, errors.Is(err, bus.ErrMultipleHandlers))
+}
+
+func TestBus_SubscribeRaw_DuplicateSubscriptionCheck(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDuplicateSubscriptionCheck(false))
+	assert.NoError(t, b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, getUserHandler))
+
+	err := b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, getUserHandler)
+
+	assert.True(t, errors.Is(err, bus.ErrAlreadySubscribed))
+}
+
+func TestBus_SubscribeRaw_Closed(t *testing.T) {
+	b := bus.New()
+	assert.NoError(t, b.Close(context.Background()))
+
+	err := b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, getUserHandler)
+
+	assert.Equal(t, bus.ErrBusClosed, err)
+}
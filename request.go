@@ -0,0 +1,94 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// requestKeyPrefix prefixes the handler keys of request handlers, which are keyed separately from the handlers
+// subscribed to the message type because they return a result
+const requestKeyPrefix = "request:"
+
+type replyKey struct{}
+
+// reply holds the result returned by the handler of a request
+type reply struct {
+	value interface{}
+}
+
+func (e *eventBus) SubscribeRequest(fn interface{}, opts ...SubscribeOption) error {
+	if err := validateHandler(fn); err != nil {
+		return err
+	}
+	fnType := reflect.TypeOf(fn)
+	if fnType.NumOut() != 2 || fnType.Out(1) != errorType {
+		return errors.New("request handlers must return a result and an error")
+	}
+	if err := e.waitSubscription(context.Background()); err != nil {
+		return err
+	}
+	if e.closed.Load() {
+		return ErrBusClosed
+	}
+
+	in := make([]reflect.Type, fnType.NumIn())
+	for i := range in {
+		in[i] = fnType.In(i)
+	}
+	handlerFn := reflect.ValueOf(fn)
+	wrapped := reflect.MakeFunc(reflect.FuncOf(in, []reflect.Type{errorType}, false), func(args []reflect.Value) []reflect.Value {
+		out := handlerFn.Call(args)
+		if r, ok := args[0].Interface().(context.Context).Value(replyKey{}).(*reply); ok {
+			r.value = out[0].Interface()
+		}
+		return out[1:]
+	})
+
+	key := requestKeyPrefix + fnType.In(1).String()
+	h := e.newHandler(wrapped.Interface(), withOptions(handler{source: fn, exclusive: true}, opts))
+	if _, err := e.handlers.AddChecked(key, h, false); err != nil {
+		return fmt.Errorf("%w: request handler %s for '%s'", err, h.name, fnType.In(1))
+	}
+	e.logSubscription(h, key)
+	return nil
+}
+
+func (e *eventBus) Request(ctx context.Context, query Message) (interface{}, error) {
+	query, ok := e.filter(ctx, query)
+	if !ok {
+		return nil, nil
+	}
+	r := &reply{}
+	key := requestKeyPrefix + reflect.TypeOf(query).String()
+	if err := e.publishKey(context.WithValue(ctx, replyKey{}, r), key, query, stopOnError, publishDefault); err != nil {
+		return nil, err
+	}
+	return r.value, nil
+}
+
+// SubscribeRequestTo subscribes fn as the request handler of *Q. It is a type safe alternative to
+// Subscriber.SubscribeRequest
+//
+//	err := bus.SubscribeRequestTo(msgBus, func(ctx context.Context, query *GetUserQuery) (*User, error) {
+//	   return &User{ID: query.ID}, nil
+//	})
+func SubscribeRequestTo[Q, R any](b Bus, fn func(ctx context.Context, query *Q) (R, error), opts ...SubscribeOption) error {
+	return b.SubscribeRequest(fn, opts...)
+}
+
+// Request sends query to its request handler and returns the result as an R. It is a type safe alternative to
+// Publisher.Request, an error is returned if the handler result is not an R.
+func Request[Q, R any](ctx context.Context, b Bus, query *Q) (R, error) {
+	var result R
+	value, err := b.Request(ctx, query)
+	if err != nil || value == nil {
+		return result, err
+	}
+	result, ok := value.(R)
+	if !ok {
+		return result, fmt.Errorf("request %T returned %T, not %s", query, value, reflect.TypeOf((*R)(nil)).Elem())
+	}
+	return result, nil
+}
@@ -0,0 +1,90 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_Request(t *testing.T) {
+	b := bus.New()
+	err := b.SubscribeRequest(func(ctx context.Context, query *GetUserQuery) (UserResult, error) {
+		if query.ID != "1234" {
+			return UserResult{}, errors.New("user not found")
+		}
+		return UserResult{Name: "Jan"}, nil
+	})
+	assert.NoError(t, err)
+
+	result, err := b.Request(context.Background(), &GetUserQuery{ID: "1234"})
+	assert.NoError(t, err)
+	assert.Equal(t, UserResult{Name: "Jan"}, result)
+
+	_, err = b.Request(context.Background(), &GetUserQuery{ID: "5678"})
+	assert.EqualError(t, err, "user not found")
+}
+
+func TestBus_RequestWithoutHandler(t *testing.T) {
+	b := bus.New()
+	assert.NoError(t, b.Subscribe(func(ctx context.Context, query *GetUserQuery) error { return nil }))
+
+	_, err := b.Request(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.True(t, errors.Is(err, bus.ErrHandlerNotFound))
+}
+
+func TestBus_SubscribeRequestRejectsSecondHandler(t *testing.T) {
+	b := bus.New()
+	handler := func(ctx context.Context, query *GetUserQuery) (UserResult, error) { return UserResult{}, nil }
+	assert.NoError(t, b.SubscribeRequest(handler))
+
+	err := b.SubscribeRequest(handler)
+
+	assert.True(t, errors.Is(err, bus.ErrMultipleHandlers))
+}
+
+func TestBus_SubscribeRequestRequiresResult(t *testing.T) {
+	b := bus.New()
+
+	err := b.SubscribeRequest(func(ctx context.Context, query *GetUserQuery) error { return nil })
+
+	assert.Error(t, err)
+}
+
+func TestRequest(t *testing.T) {
+	b := bus.New()
+	err := bus.SubscribeRequestTo(b, func(ctx context.Context, query *GetUserQuery) (*UserResult, error) {
+		return &UserResult{Name: "Jan"}, nil
+	})
+	assert.NoError(t, err)
+
+	result, err := bus.Request[GetUserQuery, *UserResult](context.Background(), b, &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, &UserResult{Name: "Jan"}, result)
+}
+
+func TestRequest_WrongResultType(t *testing.T) {
+	b := bus.New()
+	assert.NoError(t, bus.SubscribeRequestTo(b, func(ctx context.Context, query *GetUserQuery) (UserResult, error) {
+		return UserResult{Name: "Jan"}, nil
+	}))
+
+	_, err := bus.Request[GetUserQuery, string](context.Background(), b, &GetUserQuery{ID: "1234"})
+
+	assert.Error(t, err)
+}
+
+func TestSubscribeRequestTo_Options(t *testing.T) {
+	b := bus.New()
+
+	err := bus.SubscribeRequestTo(b, func(ctx context.Context, query *GetUserQuery) (*UserResult, error) {
+		return &UserResult{Name: "Jan"}, nil
+	}, bus.WithHandlerName("getUser"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "getUser", b.Subscriptions()[0].HandlerName)
+}
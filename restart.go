@@ -0,0 +1,55 @@
+package bus
+
+import (
+	"errors"
+	"reflect"
+	"time"
+)
+
+// WithRestartBackoff recovers async handlers that panic and restarts them after a delay. The first restart is delayed
+// by initial and each consecutive panic multiplies the delay by multiplier, up to max. The delay is reset to initial
+// once the handler handles a message without panicking. Messages queued while the handler is waiting to restart
+// remain in the queue. The message that caused the panic is dead lettered with an error describing the panic.
+//
+// Without this option a panic in an async handler is recovered and the handler continues with the next message
+// without delay, see WithPanicRecovery.
+func WithRestartBackoff(initial, max time.Duration, multiplier float64) Option {
+	return func(e *eventBus) {
+		e.restartBackoff = &restartBackoff{initial: initial, max: max, multiplier: multiplier}
+	}
+}
+
+type restartBackoff struct {
+	initial    time.Duration
+	max        time.Duration
+	multiplier float64
+}
+
+// next returns the delay after delay
+func (b *restartBackoff) next(delay time.Duration) time.Duration {
+	next := time.Duration(float64(delay) * b.multiplier)
+	if next > b.max {
+		return b.max
+	}
+	return next
+}
+
+// asyncWorker returns the function that consumes the queue of an async handler
+func (e *eventBus) asyncWorker(handler handler) func(params []reflect.Value) {
+	if e.restartBackoff == nil {
+		return func(params []reflect.Value) {
+			_ = e.handleAsync(handler, params)
+		}
+	}
+	delay := e.restartBackoff.initial
+	return func(params []reflect.Value) {
+		var panicErr *PanicError
+		if !errors.As(e.handleAsync(handler, params), &panicErr) {
+			delay = e.restartBackoff.initial
+			return
+		}
+		e.logger.Warnf("restarting async handler %s in %s", handler.name, delay)
+		time.Sleep(delay)
+		delay = e.restartBackoff.next(delay)
+	}
+}
@@ -0,0 +1,71 @@
+package bus_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_WithRestartBackoff(t *testing.T) {
+	var mu sync.Mutex
+	var reasons []string
+	b := bus.NewWithOptions(
+		bus.WithRestartBackoff(20*time.Millisecond, 80*time.Millisecond, 2),
+		bus.WithTypedDeadLetterHandlers(map[interface{}]func(ctx context.Context, msg bus.Message, err error){
+			nil: func(ctx context.Context, msg bus.Message, err error) {
+				mu.Lock()
+				defer mu.Unlock()
+				reasons = append(reasons, err.Error())
+			},
+		}),
+	)
+	invoked := make(chan time.Time, 6)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		invoked <- time.Now()
+		panic("connection lost")
+	})
+
+	for i := 0; i < 6; i++ {
+		_ = b.Publish(context.Background(), &GetUserQuery{})
+	}
+
+	var times []time.Time
+	for i := 0; i < 6; i++ {
+		times = append(times, <-invoked)
+	}
+	expected := []time.Duration{20, 40, 80, 80, 80}
+	for i, delay := range expected {
+		gap := times[i+1].Sub(times[i])
+		assert.GreaterOrEqual(t, gap, delay*time.Millisecond, "restart %d", i)
+		assert.Less(t, gap, delay*time.Millisecond+25*time.Millisecond, "restart %d", i)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, reasons, "handler github.com/steinfletcher/bus_test.TestBus_WithRestartBackoff.func2 panicked: connection lost")
+}
+
+func TestBus_WithRestartBackoff_ResetsAfterSuccess(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithRestartBackoff(20*time.Millisecond, time.Second, 4))
+	invoked := make(chan time.Time, 4)
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		invoked <- time.Now()
+		if query.ID == "panic" {
+			panic("connection lost")
+		}
+	})
+
+	for _, id := range []string{"panic", "1234", "panic", "5678"} {
+		_ = b.Publish(context.Background(), &GetUserQuery{ID: id})
+	}
+
+	var times []time.Time
+	for i := 0; i < 4; i++ {
+		times = append(times, <-invoked)
+	}
+	assert.GreaterOrEqual(t, times[3].Sub(times[2]), 20*time.Millisecond)
+	assert.Less(t, times[3].Sub(times[2]), 60*time.Millisecond)
+}
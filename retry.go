@@ -0,0 +1,112 @@
+package bus
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// RetryPolicy configures how many times a failed sync handler is invoked. Errors are retried unless they are
+// classified as ActionContinue or ActionDeadLetter by PublishWithClassifier
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the handler is invoked. Values less than 2 disable retries
+	MaxAttempts int
+	// Backoff is the delay before each retry
+	Backoff time.Duration
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns a context that configures the retry policy for messages published with it. The policy takes
+// precedence over the policy set with WithDefaultRetryPolicy
+//
+//	ctx = bus.WithRetryPolicy(ctx, bus.RetryPolicy{MaxAttempts: 3, Backoff: 100 * time.Millisecond})
+//	err := msgBus.Publish(ctx, &command)
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+// WithDefaultRetryPolicy sets the retry policy for messages published without a policy in their context. By default
+// handlers are not retried
+func WithDefaultRetryPolicy(policy RetryPolicy) Option {
+	return func(e *eventBus) {
+		e.defaultRetry = policy
+	}
+}
+
+// retryPolicy returns the retry policy from the context, or the bus default
+func (e *eventBus) retryPolicy(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+	return e.defaultRetry
+}
+
+// wait sleeps for the backoff before the next attempt. It returns false if the context is done before the backoff
+// elapses
+func (p RetryPolicy) wait(ctx context.Context) bool {
+	if p.Backoff <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(p.Backoff)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// handlerRetry configures the retries of a handler subscribed with WithRetry
+type handlerRetry struct {
+	maxAttempts int
+	backoff     time.Duration
+}
+
+// WithRetry invokes the subscribed handler up to maxAttempts times until it succeeds. The delay before the first retry
+// is backoff and doubles with each retry up to a minute, or up to backoff if it is longer. Each delay is reduced by a
+// random jitter of up to half so that handlers failing together do not retry in lockstep. Retries stop when the
+// context passed to the handler is done. The error of the last attempt is returned to the publisher, or dead lettered
+// for async handlers.
+//
+// Unlike RetryPolicy, every error is retried and the retries apply to async handlers as well as sync handlers. The
+// retry policy of the bus and of the publish context is ignored for the handler.
+func WithRetry(maxAttempts int, backoff time.Duration) SubscribeOption {
+	return func(h *handler) {
+		h.retry = &handlerRetry{maxAttempts: maxAttempts, backoff: backoff}
+	}
+}
+
+// maxRetryDelay is the delay at which the doubling of the delay between the retries of a handler stops, see WithRetry
+const maxRetryDelay = time.Minute
+
+// delay returns the jittered delay before the given retry, starting at 1
+func (r *handlerRetry) delay(retry int) time.Duration {
+	if r.backoff <= 0 {
+		return 0
+	}
+	delay := r.backoff
+	// doubling stops at the max delay so that the delay cannot overflow
+	for i := 1; i < retry && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay && r.backoff <= maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay - time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// callWithRetry invokes the handler until it succeeds or its retries are exhausted
+func (h handler) callWithRetry(params []reflect.Value) []reflect.Value {
+	ctx := params[0].Interface().(context.Context)
+	result := h.callOnce(params)
+	for retry := 1; retry < h.retry.maxAttempts && resultError(result) != nil; retry++ {
+		if !(RetryPolicy{Backoff: h.retry.delay(retry)}).wait(ctx) {
+			break
+		}
+		result = h.callOnce(params)
+	}
+	return result
+}
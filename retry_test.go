@@ -0,0 +1,136 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_WithRetryPolicy(t *testing.T) {
+	tests := map[string]struct {
+		ctx              context.Context
+		expectedAttempts int
+	}{
+		"bus default": {
+			ctx:              context.Background(),
+			expectedAttempts: 2,
+		},
+		"context overrides bus default": {
+			ctx:              bus.WithRetryPolicy(context.Background(), bus.RetryPolicy{MaxAttempts: 4}),
+			expectedAttempts: 4,
+		},
+		"context disables retries": {
+			ctx:              bus.WithRetryPolicy(context.Background(), bus.RetryPolicy{}),
+			expectedAttempts: 1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b := bus.NewWithOptions(bus.WithDefaultRetryPolicy(bus.RetryPolicy{MaxAttempts: 2}))
+			var attempts int
+			_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+				attempts++
+				return errors.New("failed to get user")
+			})
+
+			err := b.Publish(test.ctx, &GetUserQuery{ID: "1234"})
+
+			assert.EqualError(t, err, "failed to get user")
+			assert.Equal(t, test.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestBus_WithRetryPolicy_Succeeds(t *testing.T) {
+	b := bus.New()
+	var attempts int
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("failed to get user")
+		}
+		return nil
+	})
+	ctx := bus.WithRetryPolicy(context.Background(), bus.RetryPolicy{MaxAttempts: 5, Backoff: 10 * time.Millisecond})
+
+	start := time.Now()
+	err := b.Publish(ctx, &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestBus_Subscribe_WithRetry(t *testing.T) {
+	b := bus.New()
+	var attempts []time.Time
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		attempts = append(attempts, time.Now())
+		if len(attempts) < 4 {
+			return errTransient
+		}
+		return nil
+	}, bus.WithRetry(5, 20*time.Millisecond))
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.Len(t, attempts, 4)
+	for i, backoff := range []time.Duration{20, 40, 80} {
+		gap := attempts[i+1].Sub(attempts[i])
+		assert.GreaterOrEqual(t, gap, backoff*time.Millisecond/2, "retry %d", i+1)
+		assert.Less(t, gap, backoff*time.Millisecond+25*time.Millisecond, "retry %d", i+1)
+	}
+}
+
+func TestBus_Subscribe_WithRetry_Exhausted(t *testing.T) {
+	b := bus.New()
+	var attempts int
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		attempts++
+		return errTransient
+	}, bus.WithRetry(3, time.Millisecond))
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, errTransient, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestBus_SubscribeAsync_WithRetry(t *testing.T) {
+	envelopes := make(chan bus.DeadLetterEnvelope, 1)
+	b := bus.New()
+	_ = b.SubscribeDeadLetter(func(ctx context.Context, envelope bus.DeadLetterEnvelope) {
+		envelopes <- envelope
+	})
+	var attempts atomic.Int32
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) error {
+		attempts.Add(1)
+		return errTransient
+	}, bus.WithRetry(3, time.Millisecond))
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	envelope := <-envelopes
+	assert.Equal(t, errTransient, envelope.Reason)
+	assert.Equal(t, 3, envelope.AttemptCount)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestBus_Subscribe_WithRetry_OverridesRetryPolicy(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithDefaultRetryPolicy(bus.RetryPolicy{MaxAttempts: 3}))
+	var attempts int
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		attempts++
+		return errTransient
+	}, bus.WithRetry(2, time.Millisecond))
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, 2, attempts)
+}
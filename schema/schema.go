@@ -0,0 +1,179 @@
+// Package schema detects changes to the fields of messages handled by a bus. The fields of each subscribed message
+// type are compared to a JSON snapshot that is committed alongside the code, so that accidental changes to message
+// contracts are reported before they reach production.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/steinfletcher/bus"
+)
+
+// ErrSchemaDrift is returned by Check when the message fields differ from the snapshot
+var ErrSchemaDrift = errors.New("message schema drift detected")
+
+// ChangeKind describes how a message changed compared to the snapshot
+type ChangeKind string
+
+const (
+	MessageAdded     ChangeKind = "message added"
+	MessageRemoved   ChangeKind = "message removed"
+	FieldAdded       ChangeKind = "field added"
+	FieldRemoved     ChangeKind = "field removed"
+	FieldTypeChanged ChangeKind = "field type changed"
+)
+
+// Drift is a single difference between the snapshot and the current message fields
+type Drift struct {
+	MessageType string
+	Field       string
+	Change      ChangeKind
+	// Before is the field type recorded in the snapshot
+	Before string
+	// After is the current field type
+	After string
+}
+
+func (d Drift) String() string {
+	switch d.Change {
+	case MessageAdded, MessageRemoved:
+		return fmt.Sprintf("%s: %s", d.MessageType, d.Change)
+	case FieldTypeChanged:
+		return fmt.Sprintf("%s.%s: %s from %s to %s", d.MessageType, d.Field, d.Change, d.Before, d.After)
+	default:
+		return fmt.Sprintf("%s.%s: %s", d.MessageType, d.Field, d.Change)
+	}
+}
+
+// snapshot maps message type names to field names to field type names
+type snapshot map[string]map[string]string
+
+// DriftDetector compares the fields of the messages subscribed on a bus against a snapshot stored on disk
+type DriftDetector struct {
+	bus          bus.Inspector
+	snapshotPath string
+}
+
+// NewDriftDetector creates a drift detector for the messages subscribed on b. The snapshot is read from and written
+// to snapshotPath
+func NewDriftDetector(b bus.Inspector, snapshotPath string) *DriftDetector {
+	return &DriftDetector{bus: b, snapshotPath: snapshotPath}
+}
+
+// Detect returns the differences between the snapshot and the current message fields, ordered by message type and
+// field name
+func (d *DriftDetector) Detect() ([]Drift, error) {
+	stored, err := d.readSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	current := d.currentSnapshot()
+
+	var drifts []Drift
+	for _, msgType := range sortedKeys(stored, current) {
+		storedFields, inStored := stored[msgType]
+		currentFields, inCurrent := current[msgType]
+		if !inStored {
+			drifts = append(drifts, Drift{MessageType: msgType, Change: MessageAdded})
+			continue
+		}
+		if !inCurrent {
+			drifts = append(drifts, Drift{MessageType: msgType, Change: MessageRemoved})
+			continue
+		}
+		for _, field := range sortedKeys(storedFields, currentFields) {
+			before, inBefore := storedFields[field]
+			after, inAfter := currentFields[field]
+			switch {
+			case !inBefore:
+				drifts = append(drifts, Drift{MessageType: msgType, Field: field, Change: FieldAdded, After: after})
+			case !inAfter:
+				drifts = append(drifts, Drift{MessageType: msgType, Field: field, Change: FieldRemoved, Before: before})
+			case before != after:
+				drifts = append(drifts, Drift{MessageType: msgType, Field: field, Change: FieldTypeChanged, Before: before, After: after})
+			}
+		}
+	}
+	return drifts, nil
+}
+
+// Check returns an error wrapping ErrSchemaDrift that describes each difference if the message fields differ from
+// the snapshot
+func (d *DriftDetector) Check() error {
+	drifts, err := d.Detect()
+	if err != nil {
+		return err
+	}
+	if len(drifts) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(drifts))
+	for i, drift := range drifts {
+		descriptions[i] = drift.String()
+	}
+	return fmt.Errorf("%w: %s", ErrSchemaDrift, strings.Join(descriptions, "; "))
+}
+
+// UpdateSnapshot writes the current message fields to the snapshot file
+func (d *DriftDetector) UpdateSnapshot() error {
+	data, err := json.MarshalIndent(d.currentSnapshot(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(d.snapshotPath, append(data, '\n'), 0644)
+}
+
+func (d *DriftDetector) readSnapshot() (snapshot, error) {
+	data, err := os.ReadFile(d.snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot '%s': %w", d.snapshotPath, err)
+	}
+	return s, nil
+}
+
+func (d *DriftDetector) currentSnapshot() snapshot {
+	s := make(snapshot)
+	for _, subscription := range d.bus.Subscriptions() {
+		msgType := subscription.MessageType
+		for msgType.Kind() == reflect.Ptr {
+			msgType = msgType.Elem()
+		}
+		if msgType.Kind() != reflect.Struct {
+			continue
+		}
+		fields := make(map[string]string)
+		for i := 0; i < msgType.NumField(); i++ {
+			field := msgType.Field(i)
+			if field.IsExported() {
+				fields[field.Name] = field.Type.String()
+			}
+		}
+		s[msgType.String()] = fields
+	}
+	return s
+}
+
+func sortedKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string]V{a, b} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package schema_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+type UserCreated struct {
+	ID    string
+	Email string
+	Age   int
+}
+
+type UserDeleted struct {
+	ID string
+}
+
+func newTestBus() bus.Bus {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, msg *UserCreated) error { return nil })
+	_ = b.Subscribe(func(ctx context.Context, msg UserDeleted) error { return nil })
+	return b
+}
+
+func TestDriftDetector_NoDrift(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "schema.json")
+	detector := schema.NewDriftDetector(newTestBus(), snapshotPath)
+
+	assert.NoError(t, detector.UpdateSnapshot())
+	drifts, err := detector.Detect()
+
+	assert.NoError(t, err)
+	assert.Empty(t, drifts)
+	assert.NoError(t, detector.Check())
+}
+
+func TestDriftDetector_Drift(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "schema.json")
+	snapshot := `{
+		"schema_test.UserCreated": {"ID": "string", "Name": "string", "Age": "string"},
+		"schema_test.UserUpdated": {"ID": "string"}
+	}`
+	assert.NoError(t, os.WriteFile(snapshotPath, []byte(snapshot), 0644))
+	detector := schema.NewDriftDetector(newTestBus(), snapshotPath)
+
+	drifts, err := detector.Detect()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []schema.Drift{
+		{MessageType: "schema_test.UserCreated", Field: "Age", Change: schema.FieldTypeChanged, Before: "string", After: "int"},
+		{MessageType: "schema_test.UserCreated", Field: "Email", Change: schema.FieldAdded, After: "string"},
+		{MessageType: "schema_test.UserCreated", Field: "Name", Change: schema.FieldRemoved, Before: "string"},
+		{MessageType: "schema_test.UserDeleted", Change: schema.MessageAdded},
+		{MessageType: "schema_test.UserUpdated", Change: schema.MessageRemoved},
+	}, drifts)
+
+	err = detector.Check()
+	assert.True(t, errors.Is(err, schema.ErrSchemaDrift))
+	assert.Contains(t, err.Error(), "schema_test.UserCreated.Age: field type changed from string to int")
+}
+
+func TestDriftDetector_UpdateSnapshot(t *testing.T) {
+	snapshotPath := filepath.Join(t.TempDir(), "schema.json")
+	assert.NoError(t, os.WriteFile(snapshotPath, []byte(`{"schema_test.UserCreated": {"ID": "int"}}`), 0644))
+	detector := schema.NewDriftDetector(newTestBus(), snapshotPath)
+	assert.Error(t, detector.Check())
+
+	assert.NoError(t, detector.UpdateSnapshot())
+
+	assert.NoError(t, detector.Check())
+}
+
+func TestDriftDetector_MissingSnapshot(t *testing.T) {
+	detector := schema.NewDriftDetector(newTestBus(), filepath.Join(t.TempDir(), "schema.json"))
+
+	_, err := detector.Detect()
+
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
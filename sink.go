@@ -0,0 +1,73 @@
+package bus
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// SinkRecord is the JSON record written by a sink for each published message
+type SinkRecord struct {
+	// Type is the message type name, e.g. *main.GetUserQuery, or for messages published with PublishRaw the message
+	// type name given to PublishRaw
+	Type string `json:"type"`
+	// Kind is how the message was published, omitted for messages published by type. Messages published with
+	// PublishTopic are KindTopic, with PublishRaw KindRaw and with Request KindRequest
+	Kind SubscriptionKind `json:"kind,omitempty"`
+	// Topic is the topic the message was published to with PublishTopic
+	Topic string `json:"topic,omitempty"`
+	// Timestamp is the time the message was published
+	Timestamp time.Time `json:"timestamp"`
+	// Message is the published message, or for messages published with PublishRaw the published data
+	Message Message `json:"message"`
+}
+
+// NewSink writes every message published to b to w as a SinkRecord followed by a newline, producing newline delimited
+// JSON. Messages are written when they are published, before they are dispatched to handlers, so messages without
+// handlers are written too.
+//
+// Write errors do not fail the publish. The first write error is returned by Close. Once closed, messages are no
+// longer written to w.
+func NewSink(b Bus, w io.Writer) io.Closer {
+	s := &sink{encoder: json.NewEncoder(w)}
+	if observer, ok := b.(publishObserver); ok {
+		id := observer.observePublish(s.write)
+		s.unobserve = func() { observer.unobservePublish(id) }
+	}
+	return s
+}
+
+type sink struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	closed  bool
+	err     error
+	// unobserve stops the bus notifying the sink of publishes. It is nil if the bus does not notify observers
+	unobserve func()
+}
+
+func (s *sink) write(key string, msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	kind, msgTypeName, topic := publishedType(key, msg)
+	record := SinkRecord{Type: msgTypeName, Kind: kind, Topic: topic, Timestamp: time.Now().UTC(), Message: msg}
+	if err := s.encoder.Encode(record); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// Close stops writing messages, unregisters the sink from the bus and returns the first write error
+func (s *sink) Close() error {
+	// unregistered before locking, as the bus holds its observer lock while writing
+	if s.unobserve != nil {
+		s.unobserve()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.err
+}
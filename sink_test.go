@@ -0,0 +1,102 @@
+package bus_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewSink(t *testing.T) {
+	b := bus.New()
+	var out bytes.Buffer
+	sink := bus.NewSink(b, &out)
+	_ = b.Subscribe(getUserHandler)
+	start := time.Now()
+
+	for _, id := range []string{"1", "2", "3"} {
+		_ = b.Publish(context.Background(), &GetUserQuery{ID: id})
+	}
+	_ = b.Publish(context.Background(), &SomeCommand{})
+	_ = b.Publish(context.Background(), &SomeCommand{})
+	assert.NoError(t, sink.Close())
+	_ = b.Publish(context.Background(), &SomeCommand{})
+
+	var types, ids []string
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var record struct {
+			Type      string          `json:"type"`
+			Timestamp time.Time       `json:"timestamp"`
+			Message   json.RawMessage `json:"message"`
+		}
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		assert.False(t, record.Timestamp.Before(start.Truncate(time.Second)))
+		types = append(types, record.Type)
+		if record.Type == "*bus_test.GetUserQuery" {
+			var query GetUserQuery
+			assert.NoError(t, json.Unmarshal(record.Message, &query))
+			ids = append(ids, query.ID)
+		}
+	}
+	assert.Equal(t, []string{
+		"*bus_test.GetUserQuery", "*bus_test.GetUserQuery", "*bus_test.GetUserQuery",
+		"*bus_test.SomeCommand", "*bus_test.SomeCommand",
+	}, types)
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+}
+
+func TestNewSink_Kinds(t *testing.T) {
+	b := bus.New()
+	var out bytes.Buffer
+	sink := bus.NewSink(b, &out)
+
+	_ = b.PublishTopic(context.Background(), "users.get", &GetUserQuery{ID: "1"})
+	_ = b.PublishRaw(context.Background(), "GetUserQuery", []byte(`{"ID":"2"}`))
+	_, _ = b.Request(context.Background(), &GetUserQuery{ID: "3"})
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "4"})
+	assert.NoError(t, sink.Close())
+
+	var records []bus.SinkRecord
+	var messages []interface{}
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var record bus.SinkRecord
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		messages = append(messages, record.Message)
+		record.Timestamp, record.Message = time.Time{}, nil
+		records = append(records, record)
+	}
+	assert.Equal(t, []bus.SinkRecord{
+		{Type: "*bus_test.GetUserQuery", Kind: bus.KindTopic, Topic: "users.get"},
+		{Type: "GetUserQuery", Kind: bus.KindRaw},
+		{Type: "*bus_test.GetUserQuery", Kind: bus.KindRequest},
+		{Type: "*bus_test.GetUserQuery"},
+	}, records)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(`{"ID":"2"}`)), messages[1])
+	assert.NotContains(t, out.String(), `"kind":"type"`)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestNewSink_WriteError(t *testing.T) {
+	b := bus.New()
+	sink := bus.NewSink(b, failingWriter{})
+	_ = b.Subscribe(getUserHandler)
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.NoError(t, err)
+	assert.EqualError(t, sink.Close(), "disk full")
+}
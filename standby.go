@@ -0,0 +1,116 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// StandbyBus is a standby bus kept warm by WarmStandby. Handlers are subscribed to it as to any bus, but messages
+// published to the primary bus are queued rather than dispatched until Failover is called.
+type StandbyBus struct {
+	Bus
+	// standby is the wrapped bus if it is an *eventBus, which can replay publishes by handler key, otherwise nil
+	standby  *eventBus
+	mu       sync.Mutex
+	queue    []queuedPublish
+	active   bool
+	failover sync.Once
+	// unobserve stops primary notifying the standby of publishes. It is nil if primary does not notify observers
+	unobserve func()
+}
+
+// WarmStandby queues every message published to primary on the returned standby, which wraps standby. The queued
+// messages are not dispatched to the handlers of standby until Failover is called, for example when primary is
+// unavailable. Messages published with PublishTopic, PublishRaw and Request are replayed the same way, to the topic,
+// raw and request handlers of standby, unless standby was not created by this package in which case only messages
+// published by type are queued. Messages are queued in memory without a bound, so Failover should be called or the standby discarded
+// when the primary is known to have processed them. Close stops queueing and closes standby.
+//
+// standby := bus.WarmStandby(primary, bus.New())
+// standby.Subscribe(getUserHandler)
+//
+// err := standby.Failover(ctx)
+func WarmStandby(primary Bus, standby Bus) *StandbyBus {
+	s := &StandbyBus{Bus: standby}
+	s.standby, _ = standby.(*eventBus)
+	if observer, ok := primary.(publishObserver); ok {
+		id := observer.observePublish(s.enqueue)
+		s.unobserve = func() { observer.unobservePublish(id) }
+	}
+	return s
+}
+
+// Close stops queueing messages published to the primary bus, discards the queued messages and closes the standby bus
+func (s *StandbyBus) Close(ctx context.Context) error {
+	s.detach()
+	s.mu.Lock()
+	s.active = true
+	s.queue = nil
+	s.mu.Unlock()
+	return s.Bus.Close(ctx)
+}
+
+// detach stops primary notifying the standby of publishes. It must not be called with mu held, as primary holds its
+// observer lock while notifying
+func (s *StandbyBus) detach() {
+	if s.unobserve != nil {
+		s.unobserve()
+	}
+}
+
+// queuedPublish is a message published to the primary bus and the handler key it was published under
+type queuedPublish struct {
+	key string
+	msg Message
+}
+
+func (s *StandbyBus) enqueue(key string, msg Message) {
+	if s.standby == nil && key != reflect.TypeOf(msg).String() {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.active {
+		s.queue = append(s.queue, queuedPublish{key: key, msg: msg})
+	}
+}
+
+// replay publishes a queued message to the standby bus under the key it was published under on the primary bus.
+// Requests are replayed without a caller waiting for the result
+func (s *StandbyBus) replay(ctx context.Context, p queuedPublish) error {
+	if p.key == reflect.TypeOf(p.msg).String() {
+		return s.Bus.Publish(ctx, p.msg)
+	}
+	return s.standby.publishKey(ctx, p.key, p.msg, stopOnError, publishDefault)
+}
+
+// Pending returns the number of queued messages that have not been dispatched
+func (s *StandbyBus) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// Failover stops queueing messages published to the primary bus, unregistering the standby from it, and publishes the queued messages to the standby bus
+// in the order they were published to the primary. All queued messages are published and the errors are returned
+// joined. Only the first call to Failover has an effect.
+func (s *StandbyBus) Failover(ctx context.Context) error {
+	var errs []error
+	s.failover.Do(func() {
+		s.detach()
+		s.mu.Lock()
+		s.active = true
+		queue := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, p := range queue {
+			if err := s.replay(ctx, p); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
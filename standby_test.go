@@ -0,0 +1,87 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarmStandby(t *testing.T) {
+	primary := bus.New()
+	_ = primary.Subscribe(getUserHandler)
+	standby := bus.WarmStandby(primary, bus.New())
+	var handled []string
+	_ = standby.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		handled = append(handled, query.ID)
+		return nil
+	})
+
+	assert.NoError(t, primary.Publish(context.Background(), &GetUserQuery{ID: "1"}))
+	assert.NoError(t, primary.Publish(context.Background(), &GetUserQuery{ID: "2"}))
+	assert.Empty(t, handled)
+	assert.Equal(t, 2, standby.Pending())
+
+	assert.NoError(t, standby.Failover(context.Background()))
+
+	assert.Equal(t, []string{"1", "2"}, handled)
+	assert.Equal(t, 0, standby.Pending())
+	assert.NoError(t, primary.Publish(context.Background(), &GetUserQuery{ID: "3"}))
+	assert.NoError(t, standby.Publish(context.Background(), &GetUserQuery{ID: "4"}))
+	assert.Equal(t, []string{"1", "2", "4"}, handled)
+}
+
+func TestWarmStandby_FailoverErrors(t *testing.T) {
+	primary := bus.New()
+	_ = primary.Subscribe(getUserHandler)
+	standby := bus.WarmStandby(primary, bus.New())
+	_ = primary.Publish(context.Background(), &GetUserQuery{ID: "1"})
+
+	err := standby.Failover(context.Background())
+
+	assert.ErrorIs(t, err, bus.ErrHandlerNotFound)
+	assert.NoError(t, standby.Failover(context.Background()))
+}
+
+func TestWarmStandby_Close(t *testing.T) {
+	primary := bus.New()
+	_ = primary.Subscribe(getUserHandler)
+	standby := bus.WarmStandby(primary, bus.New())
+	_ = primary.Publish(context.Background(), &GetUserQuery{ID: "1"})
+
+	assert.NoError(t, standby.Close(context.Background()))
+	_ = primary.Publish(context.Background(), &GetUserQuery{ID: "2"})
+
+	assert.Equal(t, 0, standby.Pending())
+	assert.Equal(t, bus.ErrBusClosed, standby.Publish(context.Background(), &GetUserQuery{ID: "3"}))
+}
+
+func TestWarmStandby_FailoverKeyedPublishes(t *testing.T) {
+	primary := bus.New()
+	standby := bus.WarmStandby(primary, bus.New())
+	var handled []string
+	_ = standby.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		handled = append(handled, "type "+query.ID)
+		return nil
+	})
+	_ = standby.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, func(ctx context.Context, query *GetUserQuery) error {
+		handled = append(handled, "raw "+query.ID)
+		return nil
+	})
+	_ = standby.SubscribeTopic("users.*", func(ctx context.Context, query *GetUserQuery) error {
+		handled = append(handled, "topic "+query.ID)
+		return nil
+	})
+	_ = standby.SubscribeRequest(func(ctx context.Context, query *GetUserQuery) (string, error) {
+		handled = append(handled, "request "+query.ID)
+		return query.ID, nil
+	})
+	_ = primary.PublishRaw(context.Background(), "GetUserQuery", []byte(`{"ID":"1"}`))
+	_ = primary.PublishTopic(context.Background(), "users.get", &GetUserQuery{ID: "2"})
+	_, _ = primary.Request(context.Background(), &GetUserQuery{ID: "3"})
+
+	assert.NoError(t, standby.Failover(context.Background()))
+
+	assert.Equal(t, []string{"raw 1", "topic 2", "request 3"}, handled)
+}
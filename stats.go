@@ -0,0 +1,39 @@
+package bus
+
+import "sync/atomic"
+
+// BusStats is a snapshot of the activity of a bus since it was created
+type BusStats struct {
+	// TotalPublished is the number of messages published, including messages without handlers and messages rejected
+	// by an execution budget. Messages discarded by a filter are not counted
+	TotalPublished uint64
+	// TotalErrors is the number of publishes that returned an error plus the number of messages that async handlers
+	// failed to handle
+	TotalErrors uint64
+	// Subscriptions is the number of subscribed handlers
+	Subscriptions int
+}
+
+// counters records the activity reported by Stats
+type counters struct {
+	published atomic.Uint64
+	errors    atomic.Uint64
+}
+
+// Stats returns the activity of b. The zero value is returned for buses not created by New
+func Stats(b Bus) BusStats {
+	e, ok := b.(*eventBus)
+	if !ok {
+		return BusStats{}
+	}
+	var subscriptions int
+	for _, key := range e.handlers.Keys() {
+		handlers, _ := e.handlers.Get(key)
+		subscriptions += len(handlers)
+	}
+	return BusStats{
+		TotalPublished: e.stats.published.Load(),
+		TotalErrors:    e.stats.errors.Load(),
+		Subscriptions:  subscriptions,
+	}
+}
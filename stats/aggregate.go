@@ -0,0 +1,24 @@
+// Package stats combines the statistics of several buses, for example to report the activity of all the buses in a
+// service on a single dashboard.
+package stats
+
+import "github.com/steinfletcher/bus"
+
+// AggregatedStats is the sum of the statistics of several buses
+type AggregatedStats struct {
+	bus.BusStats
+	// Buses is the number of buses aggregated
+	Buses int
+}
+
+// Aggregate returns the sum of bus.Stats for each of buses
+func Aggregate(buses ...bus.Bus) AggregatedStats {
+	aggregated := AggregatedStats{Buses: len(buses)}
+	for _, b := range buses {
+		s := bus.Stats(b)
+		aggregated.TotalPublished += s.TotalPublished
+		aggregated.TotalErrors += s.TotalErrors
+		aggregated.Subscriptions += s.Subscriptions
+	}
+	return aggregated
+}
@@ -0,0 +1,43 @@
+package stats_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+type OrderPlaced struct {
+	ID string
+}
+
+func TestAggregate(t *testing.T) {
+	orders, payments := bus.New(), bus.New()
+	_ = orders.Subscribe(func(ctx context.Context, event *OrderPlaced) error {
+		return nil
+	})
+	_ = payments.Subscribe(func(ctx context.Context, event *OrderPlaced) error {
+		return errors.New("payment declined")
+	})
+	for i := 0; i < 3; i++ {
+		_ = orders.Publish(context.Background(), &OrderPlaced{})
+	}
+	for i := 0; i < 2; i++ {
+		_ = payments.Publish(context.Background(), &OrderPlaced{})
+	}
+
+	aggregated := stats.Aggregate(orders, payments)
+
+	assert.Equal(t, bus.Stats(orders).TotalPublished+bus.Stats(payments).TotalPublished, aggregated.TotalPublished)
+	assert.Equal(t, stats.AggregatedStats{
+		BusStats: bus.BusStats{TotalPublished: 5, TotalErrors: 2, Subscriptions: 2},
+		Buses:    2,
+	}, aggregated)
+}
+
+func TestAggregate_NoBuses(t *testing.T) {
+	assert.Equal(t, stats.AggregatedStats{}, stats.Aggregate())
+}
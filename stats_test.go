@@ -0,0 +1,32 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		if query.ID == "" {
+			return errFatal
+		}
+		return nil
+	})
+	_ = b.SubscribeAsync(func(ctx context.Context, cmd *SomeCommand) error {
+		return errFatal
+	})
+
+	_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+	_ = b.Publish(context.Background(), &SomeCommand{})
+	_ = b.Publish(context.Background(), &UserResult{})
+
+	assert.Eventually(t, func() bool {
+		return bus.Stats(b) == bus.BusStats{TotalPublished: 4, TotalErrors: 3, Subscriptions: 2}
+	}, time.Second, time.Millisecond)
+}
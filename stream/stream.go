@@ -0,0 +1,70 @@
+// Package stream connects a bus to byte streams. Published messages can be written to an io.Writer as newline
+// delimited JSON and handlers can be subscribed from commands read from an io.Reader.
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/steinfletcher/bus"
+)
+
+// NewEventStreamWriter writes each message of the given types published to b to w as a JSON record followed by a
+// newline. Types are given as sample values, e.g. &GetUserQuery{}. Messages are written when they are published,
+// before they are dispatched to handlers, so messages without handlers or whose handlers fail are written too. The
+// writer does not subscribe to the bus. Messages published with PublishTopic, PublishRaw and Request are not written.
+//
+// Write errors do not fail the publish. The first write error is returned by Close. Once closed, messages are no
+// longer written to w.
+func NewEventStreamWriter(b bus.Bus, w io.Writer, types ...interface{}) (io.Closer, error) {
+	if len(types) == 0 {
+		return nil, errors.New("at least one message type is required")
+	}
+	s := &eventStreamWriter{encoder: json.NewEncoder(w), types: make(map[reflect.Type]bool)}
+	for _, t := range types {
+		if t == nil {
+			return nil, errors.New("message type must not be nil")
+		}
+		s.types[reflect.TypeOf(t)] = true
+	}
+	s.stop = bus.ObservePublishes(b, s.write)
+	return s, nil
+}
+
+type eventStreamWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	// types holds the message types that are written
+	types  map[reflect.Type]bool
+	closed bool
+	err    error
+	// stop stops the bus passing published messages to the writer
+	stop func()
+}
+
+func (s *eventStreamWriter) write(msg bus.Message) {
+	if !s.types[reflect.TypeOf(msg)] {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if err := s.encoder.Encode(msg); err != nil && s.err == nil {
+		s.err = err
+	}
+}
+
+// Close stops writing messages and returns the first write error
+func (s *eventStreamWriter) Close() error {
+	// stopped before locking, as the bus holds its observer lock while writing
+	s.stop()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.err
+}
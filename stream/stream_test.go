@@ -0,0 +1,99 @@
+package stream_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+type UserCreated struct {
+	ID string `json:"id"`
+}
+
+type UserDeleted struct {
+	ID string `json:"id"`
+}
+
+type Ignored struct {
+	ID string `json:"id"`
+}
+
+func TestNewEventStreamWriter(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, msg Ignored) error { return nil })
+	buf := &bytes.Buffer{}
+
+	closer, err := stream.NewEventStreamWriter(b, buf, UserCreated{}, &UserDeleted{})
+	assert.NoError(t, err)
+
+	_ = b.Publish(context.Background(), UserCreated{ID: "1"})
+	_ = b.Publish(context.Background(), UserCreated{ID: "2"})
+	_ = b.Publish(context.Background(), &UserDeleted{ID: "3"})
+	_ = b.Publish(context.Background(), Ignored{ID: "4"})
+	_ = b.Publish(context.Background(), UserCreated{ID: "5"})
+	_ = b.Publish(context.Background(), &UserDeleted{ID: "6"})
+	assert.NoError(t, closer.Close())
+	_ = b.Publish(context.Background(), UserCreated{ID: "7"})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, 5)
+	var ids []string
+	for _, line := range lines {
+		var record map[string]string
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		ids = append(ids, record["id"])
+	}
+	assert.Equal(t, []string{"1", "2", "3", "5", "6"}, ids)
+}
+
+func TestNewEventStreamWriter_NoTypes(t *testing.T) {
+	_, err := stream.NewEventStreamWriter(bus.New(), &bytes.Buffer{})
+
+	assert.EqualError(t, err, "at least one message type is required")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestNewEventStreamWriter_WriteError(t *testing.T) {
+	b := bus.New()
+	closer, _ := stream.NewEventStreamWriter(b, failingWriter{}, UserCreated{})
+
+	err := b.PublishOptional(context.Background(), UserCreated{ID: "1"})
+
+	assert.NoError(t, err)
+	assert.EqualError(t, closer.Close(), "write failed")
+}
+
+func TestNewEventStreamWriter_DoesNotSubscribe(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, msg UserCreated) error {
+		return errors.New("user exists")
+	}, bus.WithSingleHandler())
+	buf := &bytes.Buffer{}
+
+	closer, err := stream.NewEventStreamWriter(b, buf, UserCreated{}, &UserDeleted{})
+	assert.NoError(t, err)
+
+	assert.EqualError(t, b.Publish(context.Background(), UserCreated{ID: "1"}), "user exists")
+	assert.Equal(t, bus.ErrHandlerNotFound, b.Publish(context.Background(), &UserDeleted{ID: "2"}))
+	assert.Len(t, b.Subscriptions(), 1)
+	assert.NoError(t, closer.Close())
+	assert.Equal(t, "{\"id\":\"1\"}\n{\"id\":\"2\"}\n", buf.String())
+}
+
+func TestNewEventStreamWriter_NilType(t *testing.T) {
+	_, err := stream.NewEventStreamWriter(bus.New(), &bytes.Buffer{}, UserCreated{}, nil)
+
+	assert.EqualError(t, err, "message type must not be nil")
+}
@@ -0,0 +1,84 @@
+package stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/steinfletcher/bus"
+)
+
+// SubscribeCommand requests that a handler from the registry is subscribed to the bus
+type SubscribeCommand struct {
+	// Handler is the name of the handler in the registry
+	Handler string `json:"handler"`
+	// Async subscribes the handler asynchronously
+	Async bool `json:"async,omitempty"`
+	// Tenant subscribes the handler for a single tenant, see bus.Subscriber.SubscribeTenant
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// HandlerRegistry looks up handler functions by name
+type HandlerRegistry interface {
+	Lookup(name string) (interface{}, bool)
+}
+
+// MapRegistry is a HandlerRegistry backed by a map of handler names to handler functions
+type MapRegistry map[string]interface{}
+
+func (r MapRegistry) Lookup(name string) (interface{}, bool) {
+	fn, ok := r[name]
+	return fn, ok
+}
+
+// Codec creates decoders that read successive values from a stream
+type Codec interface {
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Decoder reads the next value from a stream into v. It returns io.EOF when the stream ends
+type Decoder interface {
+	Decode(v interface{}) error
+}
+
+// JSONCodec decodes a stream of JSON values
+type JSONCodec struct{}
+
+func (JSONCodec) NewDecoder(r io.Reader) Decoder {
+	return json.NewDecoder(r)
+}
+
+// SubscribeFromReader reads SubscribeCommands from r and subscribes the named handlers from registry to b as each
+// command arrives. It blocks until r is exhausted, returning nil, or until a command fails, returning the error. This
+// allows handlers to be subscribed dynamically from a network connection or pipe, for example
+//
+// err := stream.SubscribeFromReader(msgBus, conn, stream.MapRegistry{"getUser": getUserHandler}, stream.JSONCodec{})
+func SubscribeFromReader(b bus.Bus, r io.Reader, registry HandlerRegistry, codec Codec) error {
+	decoder := codec.NewDecoder(r)
+	for {
+		var command SubscribeCommand
+		if err := decoder.Decode(&command); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode subscribe command: %w", err)
+		}
+		fn, ok := registry.Lookup(command.Handler)
+		if !ok {
+			return fmt.Errorf("handler '%s' not found in registry", command.Handler)
+		}
+		var err error
+		switch {
+		case command.Tenant != "":
+			err = b.SubscribeTenant(command.Tenant, fn)
+		case command.Async:
+			err = b.SubscribeAsync(fn)
+		default:
+			err = b.Subscribe(fn)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to subscribe handler '%s': %w", command.Handler, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package stream_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/stream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeFromReader(t *testing.T) {
+	b := bus.New()
+	created := make(chan string, 1)
+	deleted := make(chan string, 1)
+	registry := stream.MapRegistry{
+		"userCreated": func(ctx context.Context, msg UserCreated) error {
+			created <- msg.ID
+			return nil
+		},
+		"userDeleted": func(ctx context.Context, msg *UserDeleted) {
+			deleted <- msg.ID
+		},
+	}
+	r, w := io.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- stream.SubscribeFromReader(b, r, registry, stream.JSONCodec{})
+	}()
+
+	_, _ = w.Write([]byte(`{"handler": "userCreated"}` + "\n"))
+	assert.Eventually(t, func() bool {
+		return len(b.Subscriptions()) == 1
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, b.Publish(context.Background(), UserCreated{ID: "1"}))
+	assert.Equal(t, "1", <-created)
+	assert.Equal(t, bus.ErrHandlerNotFound, b.Publish(context.Background(), &UserDeleted{ID: "2"}))
+
+	_, _ = w.Write([]byte(`{"handler": "userDeleted", "async": true}` + "\n"))
+	assert.Eventually(t, func() bool {
+		return len(b.Subscriptions()) == 2
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, b.Publish(context.Background(), &UserDeleted{ID: "2"}))
+	assert.Equal(t, "2", <-deleted)
+
+	_ = w.Close()
+	assert.NoError(t, <-done)
+}
+
+func TestSubscribeFromReader_UnknownHandler(t *testing.T) {
+	r := strings.NewReader(`{"handler": "unknown"}`)
+
+	err := stream.SubscribeFromReader(bus.New(), r, stream.MapRegistry{}, stream.JSONCodec{})
+
+	assert.EqualError(t, err, "handler 'unknown' not found in registry")
+}
+
+func TestSubscribeFromReader_InvalidCommand(t *testing.T) {
+	r := strings.NewReader(`{"handler": `)
+
+	err := stream.SubscribeFromReader(bus.New(), r, stream.MapRegistry{}, stream.JSONCodec{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode subscribe command")
+}
@@ -0,0 +1,133 @@
+package bus
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrSubscriptionNotFound is returned when unsubscribing a handler that is not subscribed
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// ErrUnidentifiableHandler is returned by Unsubscribe for functions that share their function pointer with unrelated
+// functions, which are method values created with reflect and functions created with reflect.MakeFunc. Unsubscribe
+// such handlers with the Subscription returned by SubscribeHandle
+var ErrUnidentifiableHandler = errors.New("handler cannot be identified by its function pointer")
+
+// Subscription is a handle to a subscribed handler
+type Subscription interface {
+	// Unsubscribe removes the handler from the bus. Messages queued for an async handler that have not been handled
+	// are discarded
+	Unsubscribe() error
+
+	// Rate returns the number of times per second the handler is invoked, as an exponentially weighted moving average
+	// over one minute. Invocations skipped by filters are not counted
+	Rate() float64
+
+	// QueueDepth returns the number of messages waiting to be handled by an async handler. It is always zero for sync
+	// handlers
+	QueueDepth() int
+}
+
+// SubscribeOption configures a single subscription, see Subscriber.Subscribe
+type SubscribeOption func(*handler)
+
+type subscription struct {
+	bus     *eventBus
+	key     string
+	id      uint64
+	rate    *rateMeter
+	metrics *handlerMetrics
+	queue   *asyncQueue
+}
+
+func (s *subscription) Rate() float64 {
+	return s.rate.Rate()
+}
+
+func (s *subscription) QueueDepth() int {
+	if s.queue == nil {
+		return 0
+	}
+	return s.queue.Len()
+}
+
+func (s *subscription) Unsubscribe() error {
+	handler, ok := s.bus.handlers.Remove(s.key, s.id)
+	if !ok {
+		return ErrSubscriptionNotFound
+	}
+	handler.stop()
+	return nil
+}
+
+func (e *eventBus) SubscribeHandle(fn interface{}, opts ...SubscribeOption) (Subscription, error) {
+	return e.subscribeHandle(fn, withOptions(handler{}, opts))
+}
+
+func (e *eventBus) SubscribeAsyncHandle(fn interface{}, opts ...SubscribeOption) (Subscription, error) {
+	return e.subscribeHandle(fn, withOptions(handler{isAsync: true}, opts))
+}
+
+func (e *eventBus) Unsubscribe(fn interface{}) error {
+	if reflect.TypeOf(fn).Kind() != reflect.Func {
+		return fmt.Errorf("'%s' is not a function", reflect.TypeOf(fn))
+	}
+	if !identifiable(fn) {
+		return fmt.Errorf("%w: %s", ErrUnidentifiableHandler, reflect.TypeOf(fn))
+	}
+	removed := e.handlers.RemoveFunc(fn)
+	if len(removed) == 0 {
+		return ErrSubscriptionNotFound
+	}
+	for _, handler := range removed {
+		handler.stop()
+	}
+	return nil
+}
+
+// TimeWindow is a period of time between Start and End. Start is inclusive and End is exclusive
+type TimeWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Contains reports whether t is within the window
+func (w TimeWindow) Contains(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+func (e *eventBus) SubscribeScheduled(fn interface{}, windows []TimeWindow) (Subscription, error) {
+	return e.subscribeHandle(fn, handler{
+		accept: func(Message) bool {
+			now := time.Now()
+			for _, window := range windows {
+				if window.Contains(now) {
+					return true
+				}
+			}
+			return false
+		},
+	})
+}
+
+// WithPriority sets the priority of the handler among the handlers of its message type. Handlers are called in
+// descending order of priority and handlers of equal priority in the order they were subscribed. The default priority
+// is 0, so a validation handler subscribed with priority 1 runs before the handlers subscribed without a priority.
+// Async handlers are queued in the same order, but as they run in their own go routines they may complete in any
+// order. Handlers subscribed with SubscribeTopic are ordered by pattern first, see SubscribeTopic
+func WithPriority(n int) SubscribeOption {
+	return func(h *handler) {
+		h.priority = n
+	}
+}
+
+// WithHandlerName sets the name the handler is reported and logged by, see SubscriptionInfo.HandlerName. By default
+// the name is the fully qualified name of the subscribed function, which does not identify functions created with
+// reflect, such as method values taken with reflect.Value.Method
+func WithHandlerName(name string) SubscribeOption {
+	return func(h *handler) {
+		h.name = name
+	}
+}
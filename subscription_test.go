@@ -0,0 +1,191 @@
+package bus_test
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_SubscribeScheduled(t *testing.T) {
+	b := bus.New()
+	var received []string
+	now := time.Now()
+	windows := []bus.TimeWindow{{Start: now.Add(-time.Minute), End: now.Add(100 * time.Millisecond)}}
+
+	_, err := b.SubscribeScheduled(func(ctx context.Context, query *GetUserQuery) error {
+		received = append(received, query.ID)
+		return nil
+	}, windows)
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "inside"}))
+	time.Sleep(150 * time.Millisecond)
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "outside"}))
+
+	assert.Equal(t, []string{"inside"}, received)
+}
+
+func TestBus_SubscribeScheduled_Unsubscribe(t *testing.T) {
+	b := bus.New()
+	var invoked bool
+	windows := []bus.TimeWindow{{Start: time.Now().Add(-time.Minute), End: time.Now().Add(time.Minute)}}
+	subscription, _ := b.SubscribeScheduled(func(ctx context.Context, query *GetUserQuery) error {
+		invoked = true
+		return nil
+	}, windows)
+
+	assert.NoError(t, subscription.Unsubscribe())
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, bus.ErrHandlerNotFound, err)
+	assert.False(t, invoked)
+	assert.Equal(t, bus.ErrSubscriptionNotFound, subscription.Unsubscribe())
+}
+
+func TestTimeWindow_Contains(t *testing.T) {
+	start := time.Date(2021, 1, 1, 9, 0, 0, 0, time.UTC)
+	window := bus.TimeWindow{Start: start, End: start.Add(time.Hour)}
+
+	assert.False(t, window.Contains(start.Add(-time.Nanosecond)))
+	assert.True(t, window.Contains(start))
+	assert.True(t, window.Contains(start.Add(30*time.Minute)))
+	assert.False(t, window.Contains(start.Add(time.Hour)))
+}
+
+func TestSubscription_Rate(t *testing.T) {
+	b := bus.New()
+	always := []bus.TimeWindow{{Start: time.Now().Add(-time.Minute), End: time.Now().Add(time.Hour)}}
+	subscription, _ := b.SubscribeScheduled(func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	}, always)
+
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+
+	assert.Greater(t, subscription.Rate(), 0.0)
+}
+
+func TestRateMeter(t *testing.T) {
+	now := time.Date(2021, 1, 1, 9, 0, 0, 0, time.UTC)
+	meter := bus.NewRateMeterWithClock(func() time.Time { return now })
+
+	assert.Equal(t, 0.0, meter.Rate())
+
+	for i := 0; i < 200; i++ {
+		now = now.Add(10 * time.Millisecond)
+		meter.Mark()
+	}
+	assert.InEpsilon(t, 100, meter.Rate(), 0.01)
+
+	rate := meter.Rate()
+	now = now.Add(time.Minute)
+	weight := (1 - math.Exp(-2.0/60)) / (1 - math.Exp(-62.0/60))
+	assert.InEpsilon(t, rate*math.Exp(-1)*weight, meter.Rate(), 1e-9)
+}
+
+func TestSubscription_Rate_Idle(t *testing.T) {
+	b := bus.New()
+	subscription, _ := b.SubscribeScheduled(func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	}, nil)
+
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+
+	assert.Zero(t, subscription.Rate())
+}
+
+func TestBus_Unsubscribe(t *testing.T) {
+	b := bus.New()
+	assert.NoError(t, b.Subscribe(getUserHandler))
+	assert.NoError(t, b.SubscribeAsync(getUserHandler))
+	assert.NoError(t, b.SubscribeTenant("acme", getUserHandler))
+	assert.NoError(t, b.Subscribe(failingHandler))
+
+	assert.NoError(t, b.Unsubscribe(getUserHandler))
+
+	assert.Len(t, b.Subscriptions(), 1)
+	assert.EqualError(t, b.Publish(context.Background(), &GetUserQuery{}), "user not found")
+	assert.Equal(t, bus.ErrSubscriptionNotFound, b.Unsubscribe(getUserHandler))
+}
+
+func TestBus_Unsubscribe_NotFunc(t *testing.T) {
+	err := bus.New().Unsubscribe(&GetUserQuery{})
+
+	assert.EqualError(t, err, "'*bus_test.GetUserQuery' is not a function")
+}
+
+func TestBus_Unsubscribe_ReflectMethodValue(t *testing.T) {
+	b := bus.New()
+	svc := reflect.ValueOf(&userService{})
+	onCreateUser := svc.MethodByName("OnCreateUser").Interface()
+	onGetUser := svc.MethodByName("OnGetUser").Interface()
+	assert.NoError(t, b.Subscribe(onCreateUser))
+	assert.NoError(t, b.Subscribe(onGetUser))
+
+	err := b.Unsubscribe(onGetUser)
+
+	assert.ErrorIs(t, err, bus.ErrUnidentifiableHandler)
+	assert.Len(t, b.Subscriptions(), 2)
+}
+
+func TestBus_SubscribeHandle(t *testing.T) {
+	b := bus.New()
+	var received []string
+	sub, err := b.SubscribeHandle(func(ctx context.Context, query *GetUserQuery) error {
+		received = append(received, query.ID)
+		return nil
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1"}))
+	assert.NoError(t, sub.Unsubscribe())
+
+	assert.Equal(t, bus.ErrHandlerNotFound, b.Publish(context.Background(), &GetUserQuery{ID: "2"}))
+	assert.Equal(t, []string{"1"}, received)
+}
+
+func TestBus_SubscribeAsyncHandle(t *testing.T) {
+	b := bus.New()
+	received := make(chan string, 1)
+	sub, err := b.SubscribeAsyncHandle(func(ctx context.Context, query *GetUserQuery) {
+		received <- query.ID
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1"}))
+	assert.Equal(t, "1", <-received)
+	assert.NoError(t, sub.Unsubscribe())
+
+	assert.Equal(t, bus.ErrHandlerNotFound, b.Publish(context.Background(), &GetUserQuery{ID: "2"}))
+}
+
+func TestBus_WithPriority(t *testing.T) {
+	b := bus.New()
+	var calls []string
+	handler := func(name string) func(ctx context.Context, query *GetUserQuery) error {
+		return func(ctx context.Context, query *GetUserQuery) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+	_ = b.Subscribe(handler("business"))
+	_ = b.Subscribe(handler("audit"), bus.WithPriority(-1))
+	_ = b.Subscribe(handler("validation"), bus.WithPriority(1))
+	_ = b.Subscribe(handler("notification"))
+
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+
+	assert.Equal(t, []string{"validation", "business", "notification", "audit"}, calls)
+}
+
+func TestWithHandlerName(t *testing.T) {
+	b := bus.New()
+
+	assert.NoError(t, b.Subscribe(getUserHandler, bus.WithHandlerName("users.GetUser")))
+
+	assert.Equal(t, "users.GetUser", b.Subscriptions()[0].HandlerName)
+}
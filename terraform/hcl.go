@@ -0,0 +1,123 @@
+// Package terraform describes the subscriptions of a bus as Terraform resources, so that changes to how a service is
+// wired can be reviewed and tracked alongside its infrastructure code.
+package terraform
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/steinfletcher/bus"
+)
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// GenerateHCL returns a resource block of type resourceName for each handler subscribed on b, e.g.
+//
+//	resource "bus_subscription" "get_user_query" {
+//	  message_type = "*users.GetUserQuery"
+//	  handler_name = "github.com/org/users.GetUser"
+//	  async        = false
+//	}
+//
+// Resources are named after the message type, followed by a number for message types with more than one handler.
+// Handlers subscribed for a tenant include a tenant attribute. Handlers subscribed with SubscribeTopic, SubscribeRaw
+// or SubscribeRequest include a kind attribute of topic, raw or request, along with the topic pattern or the raw
+// message type name as the topic and raw_type attributes.
+func GenerateHCL(b bus.Inspector, resourceName string) (string, error) {
+	if !identifier.MatchString(resourceName) {
+		return "", fmt.Errorf("invalid resource name '%s'", resourceName)
+	}
+	subscriptions := b.Subscriptions()
+	counts := map[string]int{}
+	for _, subscription := range subscriptions {
+		counts[snakeCase(subscription.MessageType)]++
+	}
+
+	var sb strings.Builder
+	seen := map[string]int{}
+	for i, subscription := range subscriptions {
+		name := snakeCase(subscription.MessageType)
+		seen[name]++
+		if counts[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, seen[name])
+		}
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "resource %s %s {\n", quote(resourceName), quote(name))
+		fmt.Fprintf(&sb, "  message_type = %s\n", quote(subscription.MessageType.String()))
+		fmt.Fprintf(&sb, "  handler_name = %s\n", quote(subscription.HandlerName))
+		fmt.Fprintf(&sb, "  async        = %t\n", subscription.Async)
+		if subscription.Tenant != "" {
+			fmt.Fprintf(&sb, "  tenant       = %s\n", quote(subscription.Tenant))
+		}
+		if subscription.Kind != bus.KindType {
+			fmt.Fprintf(&sb, "  kind         = %s\n", quote(subscription.Kind.String()))
+		}
+		if subscription.Topic != "" {
+			fmt.Fprintf(&sb, "  topic        = %s\n", quote(subscription.Topic))
+		}
+		if subscription.RawMessageType != "" {
+			fmt.Fprintf(&sb, "  raw_type     = %s\n", quote(subscription.RawMessageType))
+		}
+		sb.WriteString("}\n")
+	}
+	return sb.String(), nil
+}
+
+// snakeCase returns the name of the message type in snake case, e.g. get_user_query for *users.GetUserQuery
+func snakeCase(typ reflect.Type) string {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	name := typ.Name()
+	if name == "" {
+		name = "message"
+	}
+	var sb strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				sb.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			r = '_'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// quote returns s as an HCL string literal. Template sequences are escaped so that the value is used literally
+func quote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '"', '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '$', '%':
+			sb.WriteRune(r)
+			if i+1 < len(s) && s[i+1] == '{' {
+				sb.WriteRune(r)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,94 @@
+package terraform_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/terraform"
+	"github.com/stretchr/testify/assert"
+)
+
+type GetUserQuery struct {
+	ID string
+}
+
+type HTTPRequestReceived struct{}
+
+func GetUser(ctx context.Context, query *GetUserQuery) error {
+	return nil
+}
+
+func AuditRequest(ctx context.Context, event HTTPRequestReceived) {}
+
+func TestGenerateHCL(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(GetUser)
+	_ = b.SubscribeTenant("acme", GetUser)
+	_ = b.SubscribeAsync(AuditRequest)
+
+	hcl, err := terraform.GenerateHCL(b, "bus_subscription")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `resource "bus_subscription" "get_user_query_1" {
+  message_type = "*terraform_test.GetUserQuery"
+  handler_name = "github.com/steinfletcher/bus/terraform_test.GetUser"
+  async        = false
+}
+
+resource "bus_subscription" "get_user_query_2" {
+  message_type = "*terraform_test.GetUserQuery"
+  handler_name = "github.com/steinfletcher/bus/terraform_test.GetUser"
+  async        = false
+  tenant       = "acme"
+}
+
+resource "bus_subscription" "http_request_received" {
+  message_type = "terraform_test.HTTPRequestReceived"
+  handler_name = "github.com/steinfletcher/bus/terraform_test.AuditRequest"
+  async        = true
+}
+`, hcl)
+}
+
+func TestGenerateHCL_Kinds(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeTopic("users.*", GetUser)
+	_ = b.SubscribeRaw("GetUserQuery", bus.JSONCodec{}, GetUser)
+
+	hcl, err := terraform.GenerateHCL(b, "bus_subscription")
+
+	assert.NoError(t, err)
+	assert.Equal(t, `resource "bus_subscription" "get_user_query_1" {
+  message_type = "*terraform_test.GetUserQuery"
+  handler_name = "github.com/steinfletcher/bus/terraform_test.GetUser"
+  async        = false
+  kind         = "raw"
+  raw_type     = "GetUserQuery"
+}
+
+resource "bus_subscription" "get_user_query_2" {
+  message_type = "*terraform_test.GetUserQuery"
+  handler_name = "github.com/steinfletcher/bus/terraform_test.GetUser"
+  async        = false
+  kind         = "topic"
+  topic        = "users.*"
+}
+`, hcl)
+}
+
+func TestGenerateHCL_EscapesStrings(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeTenant(`${var.tenant} "quoted"`, GetUser)
+
+	hcl, err := terraform.GenerateHCL(b, "bus_subscription")
+
+	assert.NoError(t, err)
+	assert.Contains(t, hcl, `  tenant       = "$${var.tenant} \"quoted\""`)
+}
+
+func TestGenerateHCL_InvalidResourceName(t *testing.T) {
+	_, err := terraform.GenerateHCL(bus.New(), "bus subscription")
+
+	assert.EqualError(t, err, `invalid resource name 'bus subscription'`)
+}
@@ -0,0 +1,109 @@
+package bus
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ThroughputSampler measures the number of messages published per second for each message type. A sample is emitted
+// every interval and is keyed by the message type name. Messages published with PublishTopic and Request are counted
+// under their message type, and messages published with PublishRaw under the message type name given to PublishRaw.
+//
+// sampler := bus.NewThroughputSampler(msgBus, time.Second)
+// defer sampler.Stop()
+//
+//	for sample := range sampler.Samples() {
+//	   fmt.Println(sample["*main.GetUserQuery"])
+//	}
+type ThroughputSampler struct {
+	interval time.Duration
+	mu       sync.RWMutex
+	counters map[string]*atomic.Uint64
+	samples  chan map[string]float64
+	done     chan struct{}
+	stopOnce sync.Once
+	// unobserve stops the bus notifying the sampler of publishes. It is nil if the bus does not notify observers
+	unobserve func()
+}
+
+// publishObserver is implemented by message buses that notify observers on each publish
+type publishObserver interface {
+	observePublish(fn func(msgTypeName string, msg Message)) uint64
+	unobservePublish(id uint64)
+}
+
+// NewThroughputSampler starts sampling the throughput of the given bus. Samples that are not read before the next
+// sample is taken are dropped. Call Stop to release the sampling go routine.
+func NewThroughputSampler(b Bus, interval time.Duration) *ThroughputSampler {
+	s := &ThroughputSampler{
+		interval: interval,
+		counters: make(map[string]*atomic.Uint64),
+		samples:  make(chan map[string]float64, 1),
+		done:     make(chan struct{}),
+	}
+	if observer, ok := b.(publishObserver); ok {
+		id := observer.observePublish(s.record)
+		s.unobserve = func() { observer.unobservePublish(id) }
+	}
+	go s.run()
+	return s
+}
+
+// Samples returns the channel that receives the measured messages per second keyed by message type name. The
+// channel is closed when the sampler is stopped
+func (s *ThroughputSampler) Samples() <-chan map[string]float64 {
+	return s.samples
+}
+
+// Stop stops sampling and closes the samples channel
+func (s *ThroughputSampler) Stop() {
+	s.stopOnce.Do(func() {
+		if s.unobserve != nil {
+			s.unobserve()
+		}
+		close(s.done)
+	})
+}
+
+func (s *ThroughputSampler) record(key string, msg Message) {
+	_, msgTypeName, _ := publishedType(key, msg)
+	s.mu.RLock()
+	counter, ok := s.counters[msgTypeName]
+	s.mu.RUnlock()
+	if !ok {
+		s.mu.Lock()
+		if counter, ok = s.counters[msgTypeName]; !ok {
+			counter = &atomic.Uint64{}
+			s.counters[msgTypeName] = counter
+		}
+		s.mu.Unlock()
+	}
+	counter.Add(1)
+}
+
+func (s *ThroughputSampler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	defer close(s.samples)
+	last := time.Now()
+	for {
+		select {
+		case <-s.done:
+			return
+		case now := <-ticker.C:
+			elapsed := now.Sub(last).Seconds()
+			last = now
+			sample := make(map[string]float64)
+			s.mu.RLock()
+			for msgTypeName, counter := range s.counters {
+				sample[msgTypeName] = float64(counter.Swap(0)) / elapsed
+			}
+			s.mu.RUnlock()
+			select {
+			case s.samples <- sample:
+			default:
+			}
+		}
+	}
+}
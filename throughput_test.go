@@ -0,0 +1,66 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThroughputSampler(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return nil
+	})
+	sampler := bus.NewThroughputSampler(b, 500*time.Millisecond)
+	defer sampler.Stop()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+			}
+		}
+	}()
+
+	<-sampler.Samples()
+	sample := <-sampler.Samples()
+
+	assert.InEpsilon(t, 100, sample["*bus_test.GetUserQuery"], 0.2)
+	assert.NotContains(t, sample, "bus_test.SomeCommand")
+}
+
+func TestThroughputSampler_Kinds(t *testing.T) {
+	b := bus.New()
+	sampler := bus.NewThroughputSampler(b, 50*time.Millisecond)
+	defer sampler.Stop()
+
+	_ = b.Publish(context.Background(), &GetUserQuery{})
+	_ = b.PublishTopic(context.Background(), "users.get", &GetUserQuery{})
+	_, _ = b.Request(context.Background(), &GetUserQuery{})
+	_ = b.PublishRaw(context.Background(), "GetUserQuery", []byte(`{}`))
+	sample := <-sampler.Samples()
+
+	assert.Len(t, sample, 2)
+	assert.Contains(t, sample, "*bus_test.GetUserQuery")
+	assert.Contains(t, sample, "GetUserQuery")
+}
+
+func TestThroughputSampler_Stop(t *testing.T) {
+	sampler := bus.NewThroughputSampler(bus.New(), time.Millisecond)
+
+	sampler.Stop()
+	sampler.Stop()
+
+	for range sampler.Samples() {
+	}
+}
@@ -0,0 +1,88 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// escalatingTimeout configures the timeout of each attempt to invoke a sync handler
+type escalatingTimeout struct {
+	initial time.Duration
+	min     time.Duration
+	factor  float64
+}
+
+// WithEscalatingTimeout sets a timeout on the context passed to sync handlers. The first attempt is given the initial
+// timeout and each retry, see ActionRetry, is given the previous timeout multiplied by factor, but no less than min.
+// A factor less than 1 shortens the timeout on each retry so that retries cannot cause cascading delays.
+func WithEscalatingTimeout(initial, min time.Duration, factor float64) Option {
+	return func(e *eventBus) {
+		e.timeouts = &escalatingTimeout{initial: initial, min: min, factor: factor}
+	}
+}
+
+// Timeout returns the timeout for the given attempt, starting at 0
+func (t escalatingTimeout) Timeout(attempt int) time.Duration {
+	timeout := t.initial
+	for i := 0; i < attempt && timeout > t.min; i++ {
+		timeout = time.Duration(float64(timeout) * t.factor)
+	}
+	if timeout < t.min {
+		return t.min
+	}
+	return timeout
+}
+
+// callWithTimeout invokes the handler with the timeout for the given attempt and returns the handler error
+func (e *eventBus) callWithTimeout(handler handler, params []reflect.Value, attempt int) error {
+	if e.timeouts == nil {
+		return resultError(handler.call(params))
+	}
+	ctx, cancel := context.WithTimeout(params[0].Interface().(context.Context), e.timeouts.Timeout(attempt))
+	defer cancel()
+	withTimeout := make([]reflect.Value, len(params))
+	copy(withTimeout, params)
+	withTimeout[0] = reflect.ValueOf(ctx)
+	return resultError(handler.call(withTimeout))
+}
+
+// ErrHandlerTimeout is returned when a handler subscribed with WithTimeout does not complete within its timeout
+var ErrHandlerTimeout = errors.New("handler timed out")
+
+// WithTimeout passes the handler a context that is cancelled after d and returns an error wrapping ErrHandlerTimeout
+// if the handler has not returned by then. The handler is left running in its own go routine and its result is
+// discarded, so a slow handler does not hold up the publisher or the following handlers.
+func WithTimeout(d time.Duration) SubscribeOption {
+	return func(h *handler) {
+		h.timeout = d
+	}
+}
+
+// callWithDeadline invokes the handler in a new go routine and returns its result, or ErrHandlerTimeout if the handler
+// does not return within its timeout
+func (h handler) callWithDeadline(params []reflect.Value) []reflect.Value {
+	parent := params[0].Interface().(context.Context)
+	ctx, cancel := context.WithTimeout(parent, h.timeout)
+	defer cancel()
+	withTimeout := make([]reflect.Value, len(params))
+	copy(withTimeout, params)
+	withTimeout[0] = reflect.ValueOf(ctx)
+
+	done := make(chan []reflect.Value, 1)
+	go func() {
+		done <- h.callRecover(withTimeout)
+	}()
+	select {
+	case result := <-done:
+		return result
+	case <-ctx.Done():
+		err := parent.Err()
+		if err == nil {
+			err = fmt.Errorf("%w: %s after %s", ErrHandlerTimeout, h.name, h.timeout)
+		}
+		return []reflect.Value{reflect.ValueOf(&err).Elem()}
+	}
+}
@@ -0,0 +1,98 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus_WithEscalatingTimeout(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithEscalatingTimeout(800*time.Millisecond, 150*time.Millisecond, 0.5))
+	var timeouts []time.Duration
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		deadline, ok := ctx.Deadline()
+		assert.True(t, ok)
+		timeouts = append(timeouts, time.Until(deadline))
+		if len(timeouts) < 5 {
+			return errTransient
+		}
+		return nil
+	})
+
+	ctx := bus.WithRetryPolicy(context.Background(), bus.RetryPolicy{MaxAttempts: 5})
+
+	err := b.PublishWithClassifier(ctx, &GetUserQuery{ID: "1234"}, classifyTestErrors)
+
+	assert.NoError(t, err)
+	expected := []time.Duration{800, 400, 200, 150, 150}
+	assert.Len(t, timeouts, len(expected))
+	for i, timeout := range timeouts {
+		assert.InDelta(t, expected[i]*time.Millisecond, timeout, float64(50*time.Millisecond), "attempt %d", i)
+	}
+}
+
+func TestBus_WithEscalatingTimeout_HandlerTimesOut(t *testing.T) {
+	b := bus.NewWithOptions(bus.WithEscalatingTimeout(20*time.Millisecond, 10*time.Millisecond, 0.5))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestBus_Subscribe_WithTimeout(t *testing.T) {
+	b := bus.New()
+	release := make(chan struct{})
+	defer close(release)
+	handlerErr := make(chan error, 1)
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		<-ctx.Done()
+		handlerErr <- ctx.Err()
+		<-release
+		return nil
+	}, bus.WithTimeout(20*time.Millisecond))
+	var next bool
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		next = true
+		return nil
+	})
+	classify := func(err error) bus.ErrorAction {
+		return bus.ActionContinue
+	}
+
+	start := time.Now()
+	err := b.PublishWithClassifier(context.Background(), &GetUserQuery{ID: "1234"}, classify)
+
+	assert.NoError(t, err)
+	assert.True(t, next)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, context.DeadlineExceeded, <-handlerErr)
+}
+
+func TestBus_Subscribe_WithTimeout_Error(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	}, bus.WithTimeout(10*time.Millisecond))
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.ErrorIs(t, err, bus.ErrHandlerTimeout)
+	assert.Contains(t, err.Error(), "after 10ms")
+}
+
+func TestBus_Subscribe_WithTimeout_Completes(t *testing.T) {
+	b := bus.New()
+	_ = b.Subscribe(failingHandler, bus.WithTimeout(time.Second))
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+
+	assert.EqualError(t, err, "user not found")
+}
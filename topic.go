@@ -0,0 +1,72 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// topicKeyPrefix prefixes the handler keys of topic patterns
+const topicKeyPrefix = "topic:"
+
+func (e *eventBus) SubscribeTopic(pattern string, fn interface{}) error {
+	if pattern == "" {
+		return errors.New("topic pattern must not be empty")
+	}
+	if err := validateHandler(fn); err != nil {
+		return err
+	}
+	if err := e.waitSubscription(context.Background()); err != nil {
+		return err
+	}
+	if e.closed.Load() {
+		return ErrBusClosed
+	}
+	msgType := reflect.TypeOf(fn).In(1)
+	h := e.newHandler(fn, handler{
+		accept: func(msg Message) bool {
+			return reflect.TypeOf(msg) == msgType
+		},
+	})
+	e.handlers.Add(topicKeyPrefix+pattern, h)
+	e.logSubscription(h, topicKeyPrefix+pattern)
+	return nil
+}
+
+func (e *eventBus) PublishTopic(ctx context.Context, topic string, msg Message) error {
+	msg, ok := e.filter(ctx, msg)
+	if !ok {
+		return nil
+	}
+	return e.publishKey(ctx, topicKeyPrefix+topic, msg, stopOnError, publishDefault)
+}
+
+// topicHandlers returns the handlers subscribed to patterns matching topic, ordered by pattern
+func (e *eventBus) topicHandlers(topic string) ([]handler, bool) {
+	var matched []handler
+	for _, key := range e.handlers.Keys() {
+		pattern, ok := strings.CutPrefix(key, topicKeyPrefix)
+		if !ok || !matchTopic(pattern, topic) {
+			continue
+		}
+		handlers, _ := e.handlers.Get(key)
+		matched = append(matched, handlers...)
+	}
+	return matched, len(matched) > 0
+}
+
+// matchTopic reports whether topic matches pattern, where a "*" segment of pattern matches any single segment
+func matchTopic(pattern, topic string) bool {
+	patternSegments := strings.Split(pattern, ".")
+	topicSegments := strings.Split(topic, ".")
+	if len(patternSegments) != len(topicSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if segment != "*" && segment != topicSegments[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package bus_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type UserCreated struct {
+	ID string
+}
+
+type UserDeleted struct {
+	ID string
+}
+
+func TestBus_SubscribeTopic(t *testing.T) {
+	b := bus.New()
+	var received []string
+	_ = b.SubscribeTopic("user.created", func(ctx context.Context, event *UserCreated) error {
+		received = append(received, "created "+event.ID)
+		return nil
+	})
+	_ = b.SubscribeTopic("user.*", func(ctx context.Context, event *UserCreated) error {
+		received = append(received, "any created "+event.ID)
+		return nil
+	})
+	_ = b.SubscribeTopic("user.*", func(ctx context.Context, event *UserDeleted) error {
+		received = append(received, "any deleted "+event.ID)
+		return nil
+	})
+
+	assert.NoError(t, b.PublishTopic(context.Background(), "user.created", &UserCreated{ID: "1"}))
+	assert.NoError(t, b.PublishTopic(context.Background(), "user.deleted", &UserDeleted{ID: "2"}))
+
+	assert.Equal(t, []string{"any created 1", "created 1", "any deleted 2"}, received)
+}
+
+func TestBus_PublishTopic_NoMatch(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeTopic("user.*", func(ctx context.Context, event *UserCreated) error {
+		return nil
+	})
+
+	err := b.PublishTopic(context.Background(), "user.created.v2", &UserCreated{ID: "1"})
+
+	assert.Equal(t, bus.ErrHandlerNotFound, err)
+}
+
+func TestBus_PublishTopic_SeparateFromTypes(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeTopic("user.created", func(ctx context.Context, event *UserCreated) error {
+		return nil
+	})
+
+	assert.Equal(t, bus.ErrHandlerNotFound, b.Publish(context.Background(), &UserCreated{ID: "1"}))
+}
+
+func TestBus_SubscribeTopic_Error(t *testing.T) {
+	b := bus.New()
+	_ = b.SubscribeTopic("user.created", failingHandler)
+
+	err := b.PublishTopic(context.Background(), "user.created", &GetUserQuery{ID: "1"})
+
+	assert.EqualError(t, err, "user not found")
+	assert.EqualError(t, b.SubscribeTopic("", getUserHandler), "topic pattern must not be empty")
+}
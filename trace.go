@@ -0,0 +1,71 @@
+package bus
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Tracer records publishes and handler invocations, for example as OpenTelemetry spans, see the bustrace package
+type Tracer interface {
+	// StartPublish is called when a message is published under msgTypeName and returns the context passed to its
+	// handlers. end is called with the error returned to the publisher
+	StartPublish(ctx context.Context, msgTypeName string, msg Message) (traced context.Context, end func(err error))
+
+	// StartHandler is called before each invocation of a handler and returns the context passed to the handler. end is
+	// called with the error returned by the handler, after any retries
+	StartHandler(ctx context.Context, info HandlerInfo) (traced context.Context, end func(err error))
+
+	// Propagate returns detached carrying the trace of parent. It is called with the publish context and the context
+	// passed to async handlers, which is detached from the publish context if the bus is created with
+	// WithAsyncLockContext or WithContextInheritKeys
+	Propagate(parent, detached context.Context) context.Context
+}
+
+// HandlerInfo describes a handler invocation, see Tracer
+type HandlerInfo struct {
+	// MessageType is the name of the message type handled by the handler
+	MessageType string
+	// HandlerName is the fully qualified name of the handler function
+	HandlerName string
+	// Async is true if the handler was subscribed asynchronously
+	Async bool
+	// QueueWait is the time the message waited in the queue of an async handler. It is zero for sync invocations
+	QueueWait time.Duration
+}
+
+// WithTracer sets tracer to be notified of each publish and handler invocation. The context returned by the tracer is
+// passed on to the handlers, so handlers that publish messages continue the trace
+func WithTracer(tracer Tracer) Option {
+	return func(e *eventBus) {
+		e.tracer = tracer
+	}
+}
+
+type queuedAtKey struct{}
+
+// traceAsync returns the context passed to async handlers carrying the trace of the publish context and the time the
+// message is queued
+func (e *eventBus) traceAsync(ctx, asyncCtx context.Context) context.Context {
+	return context.WithValue(e.tracer.Propagate(ctx, asyncCtx), queuedAtKey{}, time.Now())
+}
+
+// startTrace starts tracing an invocation of the handler and returns the params with the traced context
+func (h handler) startTrace(params []reflect.Value) ([]reflect.Value, func(err error)) {
+	ctx := params[0].Interface().(context.Context)
+	info := HandlerInfo{
+		MessageType: h.messageType().String(),
+		HandlerName: h.name,
+		Async:       h.isAsync,
+	}
+	if queuedAt, ok := ctx.Value(queuedAtKey{}).(time.Time); ok && !queuedAt.IsZero() {
+		info.QueueWait = time.Since(queuedAt)
+		// messages published by the handler are not attributed the wait
+		ctx = context.WithValue(ctx, queuedAtKey{}, time.Time{})
+	}
+	ctx, end := h.tracer.StartHandler(ctx, info)
+	traced := make([]reflect.Value, len(params))
+	copy(traced, params)
+	traced[0] = reflect.ValueOf(ctx)
+	return traced, end
+}
@@ -0,0 +1,82 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type traceIDKey struct{}
+
+// recordingTracer records the traced publishes and handler invocations
+type recordingTracer struct {
+	mu       sync.Mutex
+	events   []string
+	handlers []bus.HandlerInfo
+	traceIDs []interface{}
+	done     chan struct{}
+}
+
+func (r *recordingTracer) StartPublish(ctx context.Context, msgTypeName string, msg bus.Message) (context.Context, func(err error)) {
+	r.record("publish " + msgTypeName)
+	return context.WithValue(ctx, traceIDKey{}, "trace-1"), func(err error) {
+		r.record("end publish")
+	}
+}
+
+func (r *recordingTracer) StartHandler(ctx context.Context, info bus.HandlerInfo) (context.Context, func(err error)) {
+	r.mu.Lock()
+	r.handlers = append(r.handlers, info)
+	r.traceIDs = append(r.traceIDs, ctx.Value(traceIDKey{}))
+	r.mu.Unlock()
+	return ctx, func(err error) {
+		if err != nil {
+			r.record("error " + err.Error())
+		}
+		if info.Async {
+			close(r.done)
+		}
+	}
+}
+
+func (r *recordingTracer) Propagate(parent, detached context.Context) context.Context {
+	return context.WithValue(detached, traceIDKey{}, parent.Value(traceIDKey{}))
+}
+
+func (r *recordingTracer) record(event string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func TestBus_WithTracer(t *testing.T) {
+	tracer := &recordingTracer{done: make(chan struct{})}
+	b := bus.NewWithOptions(bus.WithTracer(tracer), bus.WithAsyncLockContext(func(parent context.Context) context.Context {
+		return context.Background()
+	}))
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		return errors.New("user not found")
+	})
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	err := b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	<-tracer.done
+
+	assert.EqualError(t, err, "user not found")
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	assert.Equal(t, []string{"publish *bus_test.GetUserQuery", "error user not found", "end publish"}, tracer.events)
+	assert.Len(t, tracer.handlers, 2)
+	for _, info := range tracer.handlers {
+		assert.Equal(t, "*bus_test.GetUserQuery", info.MessageType)
+		assert.NotEmpty(t, info.HandlerName)
+	}
+	assert.Equal(t, []interface{}{"trace-1", "trace-1"}, tracer.traceIDs)
+}
@@ -0,0 +1,61 @@
+package bus
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Warmup calls every handler subscribed to each of the given message types with a zero value message, so that lazy
+// initialisation such as opening connection pools happens before the bus receives real traffic. types are given as
+// sample values, e.g. &GetUserQuery{}, pointer types are called with a pointer to a zero value. Handlers subscribed
+// asynchronously and for tenants are called too, each handler is called synchronously and its errors and panics are
+// ignored. An error wrapping ErrHandlerNotFound is returned if a type has no handlers.
+func Warmup(b Bus, types ...interface{}) error {
+	e, ok := b.(*eventBus)
+	if !ok {
+		return nil
+	}
+	keys := e.handlers.Keys()
+	for _, msgType := range types {
+		typ := reflect.TypeOf(msgType)
+		if typ == nil {
+			return errors.New("message type must not be nil")
+		}
+		msgTypeName := typ.String()
+		msg := reflect.New(typ).Elem()
+		if typ.Kind() == reflect.Ptr {
+			msg = reflect.New(typ.Elem())
+		}
+		params := []reflect.Value{reflect.ValueOf(context.Background()), msg}
+
+		var found bool
+		for _, key := range keys {
+			if key != msgTypeName && !strings.HasSuffix(key, "/"+msgTypeName) {
+				continue
+			}
+			handlers, _ := e.handlers.Get(key)
+			for _, handler := range handlers {
+				found = true
+				e.warmup(handler, e.handlerParams(handler, params))
+			}
+		}
+		if !found {
+			return fmt.Errorf("warmup %s: %w", msgTypeName, ErrHandlerNotFound)
+		}
+	}
+	return nil
+}
+
+func (e *eventBus) warmup(handler handler, params []reflect.Value) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.logger.Debugf("warmup of handler %s recovered from panic: %v", handler.name, r)
+		}
+	}()
+	if err := resultError(handler.Handler.Call(params)); err != nil {
+		e.logger.Debugf("warmup of handler %s returned error: %v", handler.name, err)
+	}
+}
@@ -0,0 +1,81 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/stretchr/testify/assert"
+)
+
+type userRepository struct {
+	once sync.Once
+	db   *struct{}
+}
+
+func (r *userRepository) GetUser(ctx context.Context, query *GetUserQuery) error {
+	r.once.Do(func() {
+		r.db = &struct{}{}
+	})
+	if query.ID == "" {
+		return errors.New("ID is required")
+	}
+	return nil
+}
+
+func TestWarmup(t *testing.T) {
+	b := bus.New()
+	repository := &userRepository{}
+	_ = b.Subscribe(repository.GetUser)
+
+	err := bus.Warmup(b, &GetUserQuery{})
+
+	assert.NoError(t, err)
+	assert.NotNil(t, repository.db)
+}
+
+func TestWarmup_AllHandlers(t *testing.T) {
+	b := bus.New()
+	var mu sync.Mutex
+	var called []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		called = append(called, name)
+	}
+	_ = b.Subscribe(func(ctx context.Context, query *GetUserQuery) error {
+		record("sync")
+		return nil
+	})
+	_ = b.SubscribeAsync(func(ctx context.Context, query *GetUserQuery) {
+		record("async")
+	})
+	_ = b.SubscribeTenant("acme", func(ctx context.Context, query *GetUserQuery) error {
+		record("tenant")
+		panic("unexpected message")
+	})
+	_ = b.Subscribe(func(ctx context.Context, query SomeCommand) error {
+		record("value")
+		return nil
+	})
+
+	err := bus.Warmup(b, &GetUserQuery{}, SomeCommand{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sync", "async", "tenant", "value"}, called)
+}
+
+func TestWarmup_HandlerNotFound(t *testing.T) {
+	err := bus.Warmup(bus.New(), &GetUserQuery{})
+
+	assert.True(t, errors.Is(err, bus.ErrHandlerNotFound))
+	assert.EqualError(t, err, "warmup *bus_test.GetUserQuery: handler not found")
+}
+
+func TestWarmup_NilType(t *testing.T) {
+	err := bus.Warmup(bus.New(), nil)
+
+	assert.EqualError(t, err, "message type must not be nil")
+}
@@ -0,0 +1,23 @@
+;; handler.wasm is assembled from this file. The handle function returns 0 if the 8th byte of the JSON encoded
+;; message is '1', otherwise it returns 1. For {"ID":"1234"} the 8th byte is the first character of the ID. alloc
+;; traps if the previous allocation has not been freed with dealloc.
+(module
+  (memory (export "memory") 1)
+  (global $next (mut i32) (i32.const 1024))
+  (global $allocated (mut i32) (i32.const 0))
+  (func (export "alloc") (param $size i32) (result i32)
+    global.get $allocated
+    if
+      unreachable
+    end
+    i32.const 1
+    global.set $allocated
+    global.get $next)
+  (func (export "handle") (param $ptr i32) (param $len i32) (result i32)
+    local.get $ptr
+    i32.load8_u offset=7
+    i32.const 49
+    i32.ne)
+  (func (export "dealloc") (param $ptr i32) (param $size i32)
+    i32.const 0
+    global.set $allocated))
@@ -0,0 +1,137 @@
+// Package wasm runs bus handlers implemented as WebAssembly modules. This allows handlers to be written in any
+// language that compiles to WebAssembly and to run untrusted handler code in a sandbox.
+//
+// The module must export its memory as "memory", an "alloc" function that takes a size in bytes and returns a pointer
+// to that many bytes of memory, a "dealloc" function that takes a pointer and size returned by alloc and frees the
+// memory, and the handler function which takes a pointer and length of the JSON encoded message and returns 0 on
+// success or a non-zero error code. The memory of each message is freed once the handler function returns.
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// HandlerError is returned by a handler when the WebAssembly function returns a non-zero code
+type HandlerError struct {
+	Function string
+	Code     uint32
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("wasm handler '%s' returned error code %d", e.Function, e.Code)
+}
+
+// WasmHandler loads the WebAssembly module at modulePath and returns a handler for messages of type T that calls the
+// exported function fnName. The handler can be passed to Subscribe. The returned closer releases the WebAssembly
+// runtime and must be called once the handler is unsubscribed
+//
+//	handler, closer, err := wasm.WasmHandler[*GetUserQuery]("handler.wasm", "handle")
+//	defer closer.Close()
+//	msgBus.Subscribe(handler)
+//
+// Calls to the module are serialised as WebAssembly modules are single threaded.
+func WasmHandler[T any](modulePath string, fnName string) (func(ctx context.Context, msg T) error, io.Closer, error) {
+	source, err := os.ReadFile(modulePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx := context.Background()
+	runtime := wazero.NewRuntime(ctx)
+	module, err := runtime.Instantiate(ctx, source)
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, nil, fmt.Errorf("failed to instantiate wasm module '%s': %w", modulePath, err)
+	}
+
+	h := &handler{
+		module:  module,
+		alloc:   module.ExportedFunction("alloc"),
+		dealloc: module.ExportedFunction("dealloc"),
+		fn:      module.ExportedFunction(fnName),
+		name:    fnName,
+	}
+	switch {
+	case module.Memory() == nil:
+		err = fmt.Errorf("wasm module '%s' does not export memory", modulePath)
+	case h.alloc == nil:
+		err = fmt.Errorf("wasm module '%s' does not export function 'alloc'", modulePath)
+	case h.dealloc == nil:
+		err = fmt.Errorf("wasm module '%s' does not export function 'dealloc'", modulePath)
+	case h.fn == nil:
+		err = fmt.Errorf("wasm module '%s' does not export function '%s'", modulePath, fnName)
+	}
+	if err != nil {
+		_ = runtime.Close(ctx)
+		return nil, nil, err
+	}
+
+	return func(ctx context.Context, msg T) error {
+		return h.call(ctx, msg)
+	}, runtimeCloser{runtime}, nil
+}
+
+// runtimeCloser closes a WebAssembly runtime and the modules instantiated in it
+type runtimeCloser struct {
+	runtime wazero.Runtime
+}
+
+func (c runtimeCloser) Close() error {
+	return c.runtime.Close(context.Background())
+}
+
+type handler struct {
+	mu      sync.Mutex
+	module  api.Module
+	alloc   api.Function
+	dealloc api.Function
+	fn      api.Function
+	name    string
+}
+
+func (h *handler) call(ctx context.Context, msg interface{}) (err error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	results, err := h.alloc.Call(ctx, uint64(len(data)))
+	if err != nil {
+		return fmt.Errorf("failed to allocate wasm memory: %w", err)
+	}
+	if len(results) != 1 {
+		return fmt.Errorf("wasm function 'alloc' returned %d results, expected 1", len(results))
+	}
+	ptr := uint32(results[0])
+	defer func() {
+		if _, deallocErr := h.dealloc.Call(ctx, uint64(ptr), uint64(len(data))); deallocErr != nil && err == nil {
+			err = fmt.Errorf("failed to free wasm memory: %w", deallocErr)
+		}
+	}()
+	if !h.module.Memory().Write(ptr, data) {
+		return fmt.Errorf("wasm memory allocation at %d of %d bytes is out of range", ptr, len(data))
+	}
+
+	results, err = h.fn.Call(ctx, uint64(ptr), uint64(len(data)))
+	if err != nil {
+		return fmt.Errorf("wasm handler '%s' failed: %w", h.name, err)
+	}
+	if len(results) != 1 {
+		return fmt.Errorf("wasm handler '%s' returned %d results, expected 1", h.name, len(results))
+	}
+	if code := uint32(results[0]); code != 0 {
+		return &HandlerError{Function: h.name, Code: code}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package wasm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/steinfletcher/bus"
+	"github.com/steinfletcher/bus/wasm"
+	"github.com/stretchr/testify/assert"
+)
+
+type GetUserQuery struct {
+	ID string
+}
+
+func TestWasmHandler(t *testing.T) {
+	handler, closer, err := wasm.WasmHandler[*GetUserQuery]("testdata/handler.wasm", "handle")
+	assert.NoError(t, err)
+	defer closer.Close()
+	b := bus.New()
+	assert.NoError(t, b.Subscribe(handler))
+
+	err = b.Publish(context.Background(), &GetUserQuery{ID: "1234"})
+	assert.NoError(t, err)
+
+	err = b.Publish(context.Background(), &GetUserQuery{ID: "9999"})
+	var handlerErr *wasm.HandlerError
+	assert.True(t, errors.As(err, &handlerErr))
+	assert.Equal(t, uint32(1), handlerErr.Code)
+	assert.EqualError(t, err, "wasm handler 'handle' returned error code 1")
+
+	// alloc traps unless the memory of the previous message was freed
+	assert.NoError(t, b.Publish(context.Background(), &GetUserQuery{ID: "1234"}))
+}
+
+func TestWasmHandler_Close(t *testing.T) {
+	handler, closer, err := wasm.WasmHandler[*GetUserQuery]("testdata/handler.wasm", "handle")
+	assert.NoError(t, err)
+
+	assert.NoError(t, closer.Close())
+
+	assert.Error(t, handler(context.Background(), &GetUserQuery{ID: "1234"}))
+}
+
+func TestWasmHandler_MissingFunction(t *testing.T) {
+	_, _, err := wasm.WasmHandler[*GetUserQuery]("testdata/handler.wasm", "missing")
+
+	assert.EqualError(t, err, "wasm module 'testdata/handler.wasm' does not export function 'missing'")
+}
+
+func TestWasmHandler_MissingModule(t *testing.T) {
+	_, _, err := wasm.WasmHandler[*GetUserQuery]("testdata/missing.wasm", "handle")
+
+	assert.Error(t, err)
+}